@@ -1,9 +1,22 @@
 package check
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCheckTimeout is how long a single check may run
+	// unless it is configured otherwise
+	DefaultCheckTimeout = 3 * time.Minute
+	// DefaultTimeout is how long all checks together may run
+	// unless it is configured otherwise
+	DefaultTimeout = 5 * time.Minute
 )
 
 // Check describes what methods various checks (gofmt, go lint, etc.)
@@ -17,6 +30,51 @@ type Check interface {
 	Percentage() (float64, []FileSummary, error)
 }
 
+// ContextCheck is a Check that stops early when its context is done
+type ContextCheck interface {
+	Check
+	// PercentageContext is like Percentage, but returns
+	// ctx.Err() once ctx is done
+	PercentageContext(ctx context.Context) (float64, []FileSummary, error)
+}
+
+// percentage runs a check until it finishes or ctx is done. Checks that
+// do not implement ContextCheck are left running in the background
+// when ctx is done first.
+func percentage(ctx context.Context, c Check) (float64, []FileSummary, error) {
+	if cc, ok := c.(ContextCheck); ok {
+		return cc.PercentageContext(ctx)
+	}
+
+	type result struct {
+		p         float64
+		summaries []FileSummary
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		p, summaries, err := c.Percentage()
+		done <- result{p, summaries, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.p, r.summaries, r.err
+	case <-ctx.Done():
+		return 0, []FileSummary{}, ctx.Err()
+	}
+}
+
+// SkipError is returned by checks that could not run at all,
+// so that they are reported as skipped rather than failed
+type SkipError struct {
+	Reason string
+}
+
+func (e *SkipError) Error() string {
+	return "skipped: " + e.Reason
+}
+
 // Score represents the result of a single check
 type Score struct {
 	Name          string        `json:"name"`
@@ -25,6 +83,32 @@ type Score struct {
 	Weight        float64       `json:"weight"`
 	Percentage    float64       `json:"percentage"`
 	Error         string        `json:"error"`
+	// TimedOut is set if the check did not finish in time,
+	// in which case it does not count towards the average
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Skipped is the reason the check did not run, if it did
+	// not, in which case it does not count towards the average
+	Skipped string `json:"skipped,omitempty"`
+	// Suppressed lists the findings that ignore directives
+	// suppressed, which do not count towards Percentage
+	Suppressed []Suppressed `json:"suppressed,omitempty"`
+	// Breakdown counts the findings by the family of their
+	// rule, for checks whose rules fall into families
+	Breakdown []FamilyCount `json:"breakdown,omitempty"`
+}
+
+// FamilyCount is the number of findings of a family of rules
+type FamilyCount struct {
+	// Family is the prefix of the rules of the family, such as "SA"
+	Family string `json:"family"`
+	Title  string `json:"title"`
+	Count  int    `json:"count"`
+}
+
+// breakdowner is implemented by checks whose rules fall
+// into families, to count their findings by family
+type breakdowner interface {
+	breakdown(summaries []FileSummary) []FamilyCount
 }
 
 // ChecksResult represents the combined result of multiple checks
@@ -35,69 +119,257 @@ type ChecksResult struct {
 	Files    int     `json:"files"`
 	Issues   int     `json:"issues"`
 	DidError bool    `json:"did_error"`
+	// Config is the configuration the checks ran with
+	Config Config `json:"config"`
+	// TimedOut lists the names of the checks that did not finish in time
+	TimedOut []string `json:"timed_out,omitempty"`
+	// Skipped lists the names of the checks that did not run
+	Skipped []string `json:"skipped,omitempty"`
+	// SkippedFiles lists the files and directories that were
+	// not checked, and why
+	SkippedFiles []SkippedFile `json:"skipped_files,omitempty"`
+	// Modules breaks the result down by module, if the directory
+	// has nested modules. The rest of the result is then the
+	// aggregate of all modules.
+	Modules []ModuleResult `json:"modules,omitempty"`
+	// Targets lists the platforms and build tags
+	// packages were analyzed for
+	Targets []string `json:"targets,omitempty"`
+	// License is the license detected in the license file,
+	// if there is one
+	License *LicenseInfo `json:"license,omitempty"`
 }
 
-// Run executes all checks on the given directory
+// Run executes all enabled checks on the given directory,
+// configured by its .goreportcard.yml file if it has one.
+// It does not modify the directory, whether cli is set or not.
 func Run(dir string, cli bool) (ChecksResult, error) {
-	filenames, skipped, err := GoFiles(dir)
+	return RunContext(context.Background(), dir, cli)
+}
+
+// Options change how checks are run, overriding the configuration file
+type Options struct {
+	// Targets are the platforms and build tags packages are analyzed
+	// for. Without targets the ones in the configuration file are
+	// used, or else the ones the build constraints of the files refer to.
+	Targets []Target
+	// VulnDB is the directory of the local Go vulnerability database
+	// the vulncheck check looks dependencies up in. The check is
+	// skipped without it when it is enabled.
+	VulnDB string
+}
+
+// RunContext is like Run, but stops waiting for checks once ctx is done,
+// or once they run out of the time they are configured to take. Checks
+// that did not finish are reported as timed out, and the result is
+// computed from the ones that did.
+//
+// Nested modules are checked on their own, each with its own
+// configuration file if it has one and the one of dir otherwise.
+func RunContext(ctx context.Context, dir string, cli bool) (ChecksResult, error) {
+	return RunOptions(ctx, dir, cli, Options{})
+}
+
+// RunOptions is like RunContext, with options
+func RunOptions(ctx context.Context, dir string, cli bool, opts Options) (ChecksResult, error) {
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return ChecksResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	modules, err := findModules(dir, cfg.Exclude)
+	if err != nil {
+		return ChecksResult{}, fmt.Errorf("could not find modules: %v", err)
+	}
+	if len(modules) == 1 && modules[0].Dir == "." {
+		return runModule(ctx, dir, dir, cfg, opts)
+	}
+
+	return runModules(ctx, dir, cfg, modules, opts)
+}
+
+// runModule runs the checks on the module in dir, which is
+// root or one of the modules nested in it
+func runModule(ctx context.Context, root, dir string, cfg Config, opts Options) (ChecksResult, error) {
+	// skipped files are left in place, and are only left out of the
+	// files checks are asked about, so the tree is never modified
+	filenames, skipped, err := GoFiles(dir, cfg.Exclude...)
 	if err != nil {
 		return ChecksResult{}, fmt.Errorf("could not get filenames: %v", err)
 	}
 	if len(filenames) == 0 {
-		return ChecksResult{}, fmt.Errorf("no .go files found")
+		return ChecksResult{}, errNoGoFiles
+	}
+	for i := range skipped {
+		skipped[i].Filename = displayFilename(strings.TrimPrefix(skipped[i].Filename, "_repos/src"))
 	}
 
-	err = RenameFiles(skipped)
+	igs, err := loadIgnores(filenames)
 	if err != nil {
-		log.Println("Could not rename files:", err)
+		return ChecksResult{}, fmt.Errorf("could not read ignore directives: %v", err)
 	}
 
-	if cli {
-		defer RevertFiles(skipped)
+	sc, err := newScoring(cfg.scoring(), filenames)
+	if err != nil {
+		return ChecksResult{}, fmt.Errorf("could not count lines: %v", err)
+	}
+
+	license := License{Dir: dir, Root: root, Filenames: []string{}}
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		targets = cfg.targets(filenames)
 	}
 
-	checks := []Check{
+	// packages are loaded once for every target and shared
+	// by the checks that run analyzers in-process
+	driver := NewDriver(dir, targets...)
+	driver.Context = ctx
+
+	// golint, go_vet_extended, cognitive, staticcheck, tests,
+	// doc_coverage, license_headers, vulncheck, length, dupl and
+	// deprecated are disabled by default, see defaultChecks
+	lengths := cfg.Thresholds("length")
+	all := []Check{
 		GoFmt{Dir: dir, Filenames: filenames},
-		GoVet{Dir: dir, Filenames: filenames},
-		// GoLint{Dir: dir, Filenames: filenames},
-		GoCyclo{Dir: dir, Filenames: filenames},
-		License{Dir: dir, Filenames: []string{}},
+		GoVet{Dir: dir, Filenames: filenames, Driver: driver},
+		GoVetExtended{Dir: dir, Filenames: filenames, Driver: driver},
+		GoLint{Dir: dir, Filenames: filenames, Driver: driver},
+		GoCyclo{Dir: dir, Filenames: filenames, Over: cfg.Threshold("gocyclo")},
+		Cognitive{Dir: dir, Filenames: filenames, Over: cfg.Threshold("cognitive")},
+		license,
 		Misspell{Dir: dir, Filenames: filenames},
 		IneffAssign{Dir: dir, Filenames: filenames},
-		// Staticcheck{Dir: dir, Filenames: filenames},
-		// ErrCheck{Dir: dir, Filenames: filenames}, // disable errcheck for now, too slow and not finalized
+		Staticcheck{Dir: dir, Filenames: filenames, Driver: driver, Families: cfg.Families("staticcheck")},
+		ErrCheck{Dir: dir, Filenames: filenames, Driver: driver, Exclude: cfg.Excludes("errcheck")},
+		&Tests{Dir: dir, Filenames: filenames, Driver: driver},
+		&DocCoverage{Dir: dir, Filenames: filenames},
+		LicenseHeaders{Dir: dir, Root: root, Filenames: filenames},
+		Vulns{Dir: dir, Filenames: filenames, DB: opts.VulnDB, Target: targets[0]},
+		Length{
+			Dir:        dir,
+			Filenames:  filenames,
+			Statements: lengths["statements"],
+			Lines:      lengths["lines"],
+			FileLines:  lengths["file_lines"],
+		},
+		&Dupl{Dir: dir, Filenames: filenames, Over: cfg.Threshold("dupl")},
+		Deprecated{Dir: dir, Filenames: filenames, Driver: driver},
+	}
+
+	var checks []Check
+	for i := range all {
+		all[i] = cfg.configure(all[i])
+		if cfg.Enabled(all[i].Name()) {
+			checks = append(checks, all[i])
+		}
+	}
+	if len(checks) == 0 {
+		return ChecksResult{}, fmt.Errorf("no checks enabled")
 	}
 
 	ch := make(chan Score)
 	for _, c := range checks {
 		go func(c Check) {
-			p, summaries, err := c.Percentage()
-			errMsg := ""
-			if err != nil {
-				log.Printf("ERROR: (%s) %v", c.Name(), err)
-				errMsg = err.Error()
-			}
+			timeout := cfg.checkTimeout(c.Name())
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			p, summaries, err := percentage(checkCtx, c)
+			var skip *SkipError
 			s := Score{
 				Name:          c.Name(),
 				Description:   c.Description(),
 				FileSummaries: summaries,
 				Weight:        c.Weight(),
 				Percentage:    p,
-				Error:         errMsg,
+			}
+			switch {
+			case err != nil && checkCtx.Err() != nil:
+				log.Printf("ERROR: (%s) timed out", c.Name())
+				s.TimedOut = true
+				s.Percentage = 0
+				s.FileSummaries = []FileSummary{}
+				s.Error = fmt.Sprintf("timed out after %s", timeout)
+				if ctx.Err() != nil {
+					s.Error = "timed out waiting for all checks to finish"
+				}
+			case errors.As(err, &skip):
+				log.Printf("(%s) %v", c.Name(), err)
+				s.Skipped = skip.Reason
+				s.Percentage = 0
+				s.FileSummaries = []FileSummary{}
+			case err != nil:
+				log.Printf("ERROR: (%s) %v", c.Name(), err)
+				s.Error = err.Error()
+			default:
+				s.FileSummaries, s.Suppressed = igs.apply(c.Name(), summaries)
+				// checks score their findings by files, so they are
+				// scored again to leave out the suppressed ones or
+				// to score by density
+				if len(s.Suppressed) > 0 || sc.mode != ScoringFiles {
+					s.Percentage, err = sc.score(c, s.FileSummaries)
+					if err != nil {
+						log.Printf("ERROR: (%s) %v", c.Name(), err)
+						s.Error = err.Error()
+					}
+				}
+				s.Breakdown = breakdown(c, s.FileSummaries)
 			}
 			ch <- s
 		}(c)
 	}
 
 	resp := ChecksResult{
-		Files: len(filenames),
+		Files:        len(filenames),
+		Config:       cfg.effective(all),
+		SkippedFiles: skipped,
+	}
+	for _, t := range targets {
+		resp.Targets = append(resp.Targets, t.String())
+	}
+	resp.License = licenseInfo(dir, root)
+
+	var scores []Score
+	for i := 0; i < len(checks); i++ {
+		scores = append(scores, <-ch)
 	}
+	resp.tally(scores)
+
+	return resp, nil
+}
 
+// breakdown counts the findings of a check by the family of
+// their rule, if its rules fall into families
+func breakdown(c Check, summaries []FileSummary) []FamilyCount {
+	if w, ok := c.(weighted); ok {
+		c = w.Check
+	}
+	if b, ok := c.(breakdowner); ok {
+		return b.breakdown(summaries)
+	}
+
+	return nil
+}
+
+// tally sets the checks of the result to scores, and
+// computes the average, grade and issues from them
+func (resp *ChecksResult) tally(scores []Score) {
 	var total, totalWeight float64
 	var issues = make(map[string]bool)
-	for i := 0; i < len(checks); i++ {
-		s := <-ch
+	for _, s := range scores {
 		resp.Checks = append(resp.Checks, s)
+		if s.TimedOut {
+			resp.TimedOut = append(resp.TimedOut, s.Name)
+			continue
+		}
+		if s.Skipped != "" {
+			resp.Skipped = append(resp.Skipped, s.Name)
+			continue
+		}
 		total += s.Percentage * s.Weight
 		totalWeight += s.Weight
 		for _, fs := range s.FileSummaries {
@@ -107,14 +379,19 @@ func Run(dir string, cli bool) (ChecksResult, error) {
 			resp.DidError = true
 		}
 	}
-	total /= totalWeight
+	if totalWeight > 0 {
+		total /= totalWeight
+	}
+	if len(resp.TimedOut)+len(resp.Skipped) == len(scores) {
+		resp.DidError = true
+	}
+	sort.Strings(resp.TimedOut)
+	sort.Strings(resp.Skipped)
 
 	sort.Sort(ByWeight(resp.Checks))
 	resp.Average = total
 	resp.Issues = len(issues)
 	resp.Grade = GradeFromPercentage(total * 100)
-
-	return resp, nil
 }
 
 // ByWeight implements sorting for checks by weight descending
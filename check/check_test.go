@@ -1,7 +1,11 @@
 package check
 
 import (
+	"context"
+	"errors"
+	"os"
 	"testing"
+	"time"
 )
 
 func TestRun(t *testing.T) {
@@ -14,3 +18,81 @@ func TestRun(t *testing.T) {
 		t.Errorf("got cr.Issues = %d, want %d", cr.Issues, 2)
 	}
 }
+
+// slowCheck is a check that does not support contexts
+// and takes a while to finish
+type slowCheck struct {
+	delay time.Duration
+}
+
+func (s slowCheck) Name() string        { return "slow" }
+func (s slowCheck) Description() string { return "" }
+func (s slowCheck) Weight() float64     { return 1 }
+
+func (s slowCheck) Percentage() (float64, []FileSummary, error) {
+	time.Sleep(s.delay)
+	return 1, []FileSummary{}, nil
+}
+
+func TestPercentageContext(t *testing.T) {
+	cases := []struct {
+		check   Check
+		timeout time.Duration
+		want    float64
+		err     error
+	}{
+		{slowCheck{delay: time.Millisecond}, time.Minute, 1, nil},
+		{slowCheck{delay: time.Minute}, time.Millisecond, 0, context.DeadlineExceeded},
+		{GoCyclo{Filenames: []string{"testdata/cyclo/a.go"}}, time.Minute, 15.0 / 17.0, nil},
+		{GoCyclo{Filenames: []string{"testdata/cyclo/a.go"}}, 0, 0, context.DeadlineExceeded},
+	}
+	for _, tt := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+		p, _, err := percentage(ctx, tt.check)
+		cancel()
+		if !errors.Is(err, tt.err) {
+			t.Errorf("percentage(%s) with timeout %s: err = %v, want %v", tt.check.Name(), tt.timeout, err, tt.err)
+		}
+		if p != tt.want {
+			t.Errorf("percentage(%s) with timeout %s = %f, want %f", tt.check.Name(), tt.timeout, p, tt.want)
+		}
+	}
+}
+
+func TestChecksTakeContext(t *testing.T) {
+	checks := []Check{
+		GoFmt{}, GoVet{}, GoVetExtended{}, GoLint{}, GoCyclo{}, Cognitive{}, License{},
+		Misspell{}, IneffAssign{}, Staticcheck{}, ErrCheck{}, &Tests{}, &DocCoverage{},
+		LicenseHeaders{}, Vulns{}, Length{}, &Dupl{}, Deprecated{},
+	}
+	for _, c := range checks {
+		if _, ok := c.(ContextCheck); !ok {
+			t.Errorf("%s does not implement ContextCheck", c.Name())
+		}
+	}
+}
+
+func TestRunLeavesTreeUnchanged(t *testing.T) {
+	dir := "testdata/testfiles"
+	before, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Run(dir, false); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("Run changed the files in %s from %d to %d", dir, len(before), len(after))
+	}
+	for i := range before {
+		if before[i].Name() != after[i].Name() {
+			t.Errorf("Run renamed %s to %s", before[i].Name(), after[i].Name())
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// cognitiveOver is the default cognitive complexity
+// above which functions are reported
+const cognitiveOver = 15
+
+// Cognitive is the check for the cognitive complexity of functions,
+// which unlike cyclomatic complexity penalizes nesting and counts
+// a switch once however many cases it has
+type Cognitive struct {
+	Dir       string
+	Filenames []string
+	// Over is the complexity above which functions are
+	// reported, cognitiveOver if it is not set
+	Over int
+
+	metricScoring
+}
+
+func (c Cognitive) over() int {
+	if c.Over == 0 {
+		return cognitiveOver
+	}
+
+	return c.Over
+}
+
+// Name returns the name of the display name of the command
+func (c Cognitive) Name() string {
+	return "cognitive"
+}
+
+// Weight returns the weight this check has in the overall average
+func (c Cognitive) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the average score of the .go files, where a file
+// without complex functions scores 1 and every function over the
+// threshold scales its score down by how far over the threshold it is
+func (c Cognitive) Percentage() (float64, []FileSummary, error) {
+	return c.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (c Cognitive) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	over := c.over()
+	var failed = []FileSummary{}
+	err := eachFile(ctx, c.Filenames, 0, func(fn string, _ []byte, fset *token.FileSet, f *ast.File) error {
+		filename := strings.TrimPrefix(fn, "_repos/src")
+		fs := FileSummary{
+			Filename: displayFilename(filename),
+			FileURL:  fileURL(filename),
+		}
+		for _, s := range cognitiveStats(fset, f, over) {
+			if s.Over == 0 {
+				continue
+			}
+			e := ruleError("cognitive", s.StartLine, 0, fmt.Sprintf("warning: cognitive complexity %d of function %s() is high (> %d) (cognitive)", s.Value, s.Name, over))
+			e.EndLine = s.EndLine
+			e.Function = &s
+			fs.Errors = append(fs.Errors, e)
+		}
+		if len(fs.Errors) > 0 {
+			failed = append(failed, fs)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	return metricScore(c.Filenames, failed), failed, nil
+}
+
+// cognitiveStats returns the cognitive complexity of every function
+// in a file, measured against over, most complex first
+func cognitiveStats(fset *token.FileSet, f *ast.File, over int) []FunctionMetric {
+	var metrics []FunctionMetric
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+
+		v := &cognitiveVisitor{fset: fset}
+		v.visit(fd.Body)
+		m := FunctionMetric{
+			Name:          strings.TrimSuffix(funcName(fd), "()"),
+			Metric:        "cognitive complexity",
+			Threshold:     over,
+			StartLine:     fset.Position(fd.Pos()).Line,
+			EndLine:       fset.Position(fd.End()).Line,
+			Contributions: v.contributions,
+		}
+		for _, c := range v.contributions {
+			m.Value += c.Increment
+		}
+		if m.Value > m.Threshold {
+			m.Over = m.Value - m.Threshold
+		}
+		metrics = append(metrics, m)
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Value > metrics[j].Value
+	})
+
+	return metrics
+}
+
+// cognitiveVisitor adds up the cognitive complexity of a function.
+// Breaks in the linear flow of the code, such as if, for and switch
+// statements, cost one, plus one for every level they are nested in.
+// Else branches, labeled jumps, goto and every sequence of like
+// boolean operators cost one whatever their nesting.
+type cognitiveVisitor struct {
+	fset          *token.FileSet
+	nesting       int
+	contributions []Contribution
+}
+
+// add records an increment for the construct at pos
+func (v *cognitiveVisitor) add(pos token.Pos, reason string, nested bool) {
+	c := Contribution{Line: v.fset.Position(pos).Line, Reason: reason, Increment: 1}
+	if nested {
+		c.Nesting = v.nesting
+		c.Increment += v.nesting
+	}
+	v.contributions = append(v.contributions, c)
+}
+
+// nested visits a node one level of nesting deeper
+func (v *cognitiveVisitor) nested(n ast.Node) {
+	v.nesting++
+	v.visit(n)
+	v.nesting--
+}
+
+// visit walks a node, counting the constructs that break the linear
+// flow of the code and stepping into the ones that nest it
+func (v *cognitiveVisitor) visit(n ast.Node) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			v.add(n.Pos(), "if", true)
+			v.ifStmt(n)
+			return false
+		case *ast.ForStmt:
+			v.add(n.Pos(), "for", true)
+			v.visitAll(n.Init, n.Cond, n.Post)
+			v.nested(n.Body)
+			return false
+		case *ast.RangeStmt:
+			v.add(n.Pos(), "for", true)
+			v.visitAll(n.X)
+			v.nested(n.Body)
+			return false
+		case *ast.SwitchStmt:
+			v.add(n.Pos(), "switch", true)
+			v.visitAll(n.Init, n.Tag)
+			v.nested(n.Body)
+			return false
+		case *ast.TypeSwitchStmt:
+			v.add(n.Pos(), "switch", true)
+			v.visitAll(n.Init, n.Assign)
+			v.nested(n.Body)
+			return false
+		case *ast.SelectStmt:
+			v.add(n.Pos(), "select", true)
+			v.nested(n.Body)
+			return false
+		case *ast.FuncLit:
+			v.nested(n.Body)
+			return false
+		case *ast.BranchStmt:
+			switch {
+			case n.Tok == token.GOTO:
+				v.add(n.Pos(), "goto", false)
+			case n.Label != nil:
+				v.add(n.Pos(), n.Tok.String()+" to label", false)
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				v.logical(n)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// visitAll visits the parts of a statement that are set
+func (v *cognitiveVisitor) visitAll(nodes ...ast.Node) {
+	for _, n := range nodes {
+		if n != nil {
+			v.visit(n)
+		}
+	}
+}
+
+// ifStmt visits the parts of an if statement whose own increment
+// has been added, and its else if and else branches
+func (v *cognitiveVisitor) ifStmt(n *ast.IfStmt) {
+	v.visitAll(n.Init, n.Cond)
+	v.nested(n.Body)
+	switch e := n.Else.(type) {
+	case *ast.IfStmt:
+		v.add(e.Pos(), "else if", false)
+		v.ifStmt(e)
+	case *ast.BlockStmt:
+		v.add(n.Else.Pos(), "else", false)
+		v.nested(e)
+	}
+}
+
+// logical adds one for every sequence of like operators in a
+// boolean expression, so a && b && c costs one and a && b || c two
+func (v *cognitiveVisitor) logical(x *ast.BinaryExpr) {
+	var ops []*ast.BinaryExpr
+	var operands []ast.Expr
+	var flatten func(ast.Expr)
+	flatten = func(e ast.Expr) {
+		if b, ok := ast.Unparen(e).(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+			flatten(b.X)
+			ops = append(ops, b)
+			flatten(b.Y)
+			return
+		}
+		operands = append(operands, e)
+	}
+	flatten(x)
+
+	for i, op := range ops {
+		if i == 0 || op.Op != ops[i-1].Op {
+			v.add(op.OpPos, op.Op.String(), false)
+		}
+	}
+	for _, e := range operands {
+		v.visit(e)
+	}
+}
+
+// Description returns the description of Cognitive
+func (c Cognitive) Description() string {
+	return fmt.Sprintf(`Calculates the <a href="https://www.sonarsource.com/docs/CognitiveComplexity.pdf">cognitive complexity</a> of functions, which measures how hard they are to read rather than how many paths they have.
+
+The cognitive complexity of a function is calculated according to the following rules:
+
++1 for each 'if', 'else if', 'else', 'for', 'switch', 'select', 'goto', labeled 'break' or 'continue', and sequence of like '&&' or '||' operators
++1 more for each level of nesting of an 'if', 'for', 'switch' or 'select', where function literals also nest
+
+Go Report Card warns on functions with cognitive complexity > %[1]d, and shows what contributed to it. Each of them lowers the score of its file by the ratio of %[1]d to its complexity.`, c.over())
+}
@@ -0,0 +1,59 @@
+package check
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestCognitiveStats(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "testdata/cognitive/a.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := cognitiveStats(fset, f, cognitiveOver)
+
+	want := map[string]int{"flat": 1, "nested": 14, "closure": 8}
+	got := make(map[string]int)
+	for _, s := range stats {
+		got[s.Name] = s.Value
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cognitive complexities = %v, want %v", got, want)
+	}
+}
+
+func TestCognitive(t *testing.T) {
+	c := Cognitive{Dir: "testdata/cognitive", Filenames: []string{"testdata/cognitive/a.go"}, Over: 10}
+	p, fs, err := c.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 10.0 / 14.0; p != want {
+		t.Errorf("Cognitive percent = %f, want %f", p, want)
+	}
+	if len(fs) != 1 || len(fs[0].Errors) != 1 {
+		t.Fatalf("Cognitive failed = %#v, want one error", fs)
+	}
+
+	e := fs[0].Errors[0]
+	if want := "warning: cognitive complexity 14 of function nested() is high (> 10) (cognitive)"; e.ErrorString != want {
+		t.Errorf("error = %q, want %q", e.ErrorString, want)
+	}
+	want := []Contribution{
+		{Line: 29, Reason: "for", Increment: 1},
+		{Line: 30, Reason: "for", Increment: 2, Nesting: 1},
+		{Line: 31, Reason: "if", Increment: 3, Nesting: 2},
+		{Line: 31, Reason: "&&", Increment: 1},
+		{Line: 32, Reason: "if", Increment: 4, Nesting: 3},
+		{Line: 34, Reason: "else", Increment: 1},
+		{Line: 37, Reason: "else if", Increment: 1},
+		{Line: 37, Reason: "||", Increment: 1},
+	}
+	if !reflect.DeepEqual(e.Function.Contributions, want) {
+		t.Errorf("contributions = %+v, want %+v", e.Function.Contributions, want)
+	}
+}
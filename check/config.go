@@ -0,0 +1,396 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFilename is the name of the optional configuration
+// file read from the root of the module being checked
+const ConfigFilename = ".goreportcard.yml"
+
+// Config controls which checks run, how they are weighted
+// and which files they see
+type Config struct {
+	// Checks configures checks by name
+	Checks map[string]CheckConfig `yaml:"checks" json:"checks"`
+	// Exclude lists glob patterns of files and directories to skip,
+	// on top of the ones that are always skipped. Patterns without a
+	// slash match the base name of a file or directory anywhere in the
+	// tree, other patterns match the path relative to the module root.
+	Exclude []string `yaml:"exclude" json:"exclude"`
+	// Timeout is how long all checks together may run,
+	// as a duration such as "5m"
+	Timeout string `yaml:"timeout" json:"timeout"`
+	// Scoring is how findings are turned into percentages,
+	// "density" or "files"
+	Scoring Scoring `yaml:"scoring" json:"scoring"`
+	// Platforms lists the platforms packages are analyzed for,
+	// such as "linux/amd64". Without them, the platforms are picked
+	// from the build constraints of the files. Tags lists sets of
+	// build tags, each a comma-separated list, that are analyzed as
+	// targets of their own on top of no tags: on every platform if
+	// platforms are listed, and on the host platform otherwise.
+	Platforms []string `yaml:"platforms" json:"platforms,omitempty"`
+	Tags      []string `yaml:"tags" json:"tags,omitempty"`
+}
+
+// CheckConfig configures a single check. Unset fields
+// keep the check's defaults.
+type CheckConfig struct {
+	Enabled *bool    `yaml:"enabled" json:"enabled"`
+	Weight  *float64 `yaml:"weight" json:"weight"`
+	// Threshold is the value above which the check reports
+	// a finding, for checks that measure something
+	Threshold *int `yaml:"threshold" json:"threshold,omitempty"`
+	// Thresholds are the values above which the check reports
+	// a finding, by what they apply to, for checks that measure
+	// several things
+	Thresholds map[string]int `yaml:"thresholds" json:"thresholds,omitempty"`
+	// Exclude lists functions whose calls the check does not
+	// report, on top of its defaults, for checks that report calls
+	Exclude []string `yaml:"exclude" json:"exclude,omitempty"`
+	// Families lists the families of rules the check runs,
+	// for checks whose rules fall into families
+	Families []string `yaml:"families" json:"families,omitempty"`
+	// Timeout is how long the check may run,
+	// as a duration such as "90s"
+	Timeout string `yaml:"timeout" json:"timeout"`
+}
+
+// checkDefaults holds the defaults of a check that can be configured
+type checkDefaults struct {
+	enabled    bool
+	threshold  int
+	thresholds map[string]int
+	exclude    []string
+	// families are the families of rules the check has,
+	// mapped to whether they run by default
+	families map[string]bool
+}
+
+// defaultChecks lists every check that can be configured, along
+// with whether it runs by default and its default thresholds
+var defaultChecks = map[string]checkDefaults{
+	"gofmt":           {enabled: true},
+	"go_vet":          {enabled: true},
+	"go_vet_extended": {enabled: false},
+	"golint":          {enabled: false},
+	"gocyclo":         {enabled: true, threshold: cycloOver},
+	"cognitive":       {enabled: false, threshold: cognitiveOver},
+	"license":         {enabled: true},
+	"misspell":        {enabled: true},
+	"ineffassign":     {enabled: true},
+	"staticcheck":     {enabled: false, families: staticcheckDefaults()},
+	"errcheck":        {enabled: true, exclude: errcheckExclude},
+	"tests":           {enabled: false},
+	"doc_coverage":    {enabled: false},
+	"license_headers": {enabled: false},
+	"vulncheck":       {enabled: false},
+	"dupl":            {enabled: false, threshold: duplOver},
+	"deprecated":      {enabled: false},
+	"length": {enabled: false, thresholds: map[string]int{
+		"statements": statementsOver,
+		"lines":      linesOver,
+		"file_lines": fileLinesOver,
+	}},
+}
+
+// LoadConfig reads the configuration file from dir, if there is one
+func LoadConfig(dir string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(filepath.Join(dir, ConfigFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("could not parse %s: %v", ConfigFilename, err)
+	}
+
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	for name, cc := range c.Checks {
+		if _, ok := defaultChecks[name]; !ok {
+			return fmt.Errorf("%s: unknown check %q", ConfigFilename, name)
+		}
+		if cc.Weight != nil && *cc.Weight < 0 {
+			return fmt.Errorf("%s: weight of %s must not be negative", ConfigFilename, name)
+		}
+		if cc.Threshold != nil && *cc.Threshold <= 0 {
+			return fmt.Errorf("%s: threshold of %s must be positive", ConfigFilename, name)
+		}
+		for key, t := range cc.Thresholds {
+			if _, ok := defaultChecks[name].thresholds[key]; !ok {
+				return fmt.Errorf("%s: unknown threshold %q of %s", ConfigFilename, key, name)
+			}
+			if t <= 0 {
+				return fmt.Errorf("%s: threshold %s of %s must be positive", ConfigFilename, key, name)
+			}
+		}
+		if len(cc.Exclude) > 0 && defaultChecks[name].exclude == nil {
+			return fmt.Errorf("%s: %s does not exclude functions", ConfigFilename, name)
+		}
+		for _, family := range cc.Families {
+			if _, ok := defaultChecks[name].families[family]; !ok {
+				return fmt.Errorf("%s: unknown rule family %q of %s", ConfigFilename, family, name)
+			}
+		}
+		if err := validateTimeout(cc.Timeout); err != nil {
+			return fmt.Errorf("%s: timeout of %s: %v", ConfigFilename, name, err)
+		}
+	}
+
+	if err := validateTimeout(c.Timeout); err != nil {
+		return fmt.Errorf("%s: timeout: %v", ConfigFilename, err)
+	}
+
+	if c.Scoring != "" {
+		if _, err := ParseScoring(string(c.Scoring)); err != nil {
+			return fmt.Errorf("%s: %v", ConfigFilename, err)
+		}
+	}
+
+	if _, err := Matrix(c.Platforms, c.Tags); err != nil {
+		return fmt.Errorf("%s: %v", ConfigFilename, err)
+	}
+
+	for _, pattern := range c.Exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid exclude pattern %q: %v", ConfigFilename, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Enabled reports whether the check with the given name runs
+func (c Config) Enabled(name string) bool {
+	if cc, ok := c.Checks[name]; ok && cc.Enabled != nil {
+		return *cc.Enabled
+	}
+
+	return defaultChecks[name].enabled
+}
+
+// Threshold returns the threshold of the check with the given name
+func (c Config) Threshold(name string) int {
+	if cc, ok := c.Checks[name]; ok && cc.Threshold != nil {
+		return *cc.Threshold
+	}
+
+	return defaultChecks[name].threshold
+}
+
+// Thresholds returns the thresholds of the check with the given
+// name, for checks that measure several things
+func (c Config) Thresholds(name string) map[string]int {
+	thresholds := make(map[string]int)
+	for key, t := range defaultChecks[name].thresholds {
+		thresholds[key] = t
+	}
+	for key, t := range c.Checks[name].Thresholds {
+		thresholds[key] = t
+	}
+
+	return thresholds
+}
+
+// Excludes returns the functions whose calls the check
+// with the given name does not report, for checks that
+// report calls
+func (c Config) Excludes(name string) []string {
+	return append(append([]string{}, defaultChecks[name].exclude...), c.Checks[name].Exclude...)
+}
+
+// Families returns the families of rules the check with the given
+// name runs, for checks whose rules fall into families
+func (c Config) Families(name string) []string {
+	if families := c.Checks[name].Families; families != nil {
+		return families
+	}
+
+	var families []string
+	for family, enabled := range defaultChecks[name].families {
+		if enabled {
+			families = append(families, family)
+		}
+	}
+	sort.Strings(families)
+
+	return families
+}
+
+// scoring returns how findings are turned into percentages
+func (c Config) scoring() Scoring {
+	if c.Scoring == "" {
+		return DefaultScoring
+	}
+
+	return c.Scoring
+}
+
+// targets returns the targets packages are analyzed for
+func (c Config) targets(filenames []string) []Target {
+	// validated when the configuration was loaded
+	targets, _ := Matrix(c.Platforms, c.Tags)
+	if len(c.Platforms) > 0 {
+		return targets
+	}
+
+	// the first target is the host platform without tags,
+	// which the default targets start with as well
+	return append(defaultTargets(filenames), targets[1:]...)
+}
+
+// timeout returns how long all checks together may run
+func (c Config) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil {
+		return d
+	}
+
+	return DefaultTimeout
+}
+
+// checkTimeout returns how long the check with the given name may run
+func (c Config) checkTimeout(name string) time.Duration {
+	if d, err := time.ParseDuration(c.Checks[name].Timeout); err == nil {
+		return d
+	}
+
+	return DefaultCheckTimeout
+}
+
+func validateTimeout(s string) error {
+	if s == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("must be positive")
+	}
+
+	return nil
+}
+
+// configure applies the configured weight to a check
+func (c Config) configure(check Check) Check {
+	if cc, ok := c.Checks[check.Name()]; ok && cc.Weight != nil {
+		return weighted{Check: check, weight: *cc.Weight}
+	}
+
+	return check
+}
+
+// effective returns the configuration that was applied to checks,
+// with every known check and all of its defaults filled in
+func (c Config) effective(checks []Check) Config {
+	weights := make(map[string]float64)
+	for _, check := range checks {
+		weights[check.Name()] = check.Weight()
+	}
+
+	eff := Config{
+		Checks:    make(map[string]CheckConfig),
+		Exclude:   append(defaultExclude(), c.Exclude...),
+		Timeout:   c.timeout().String(),
+		Scoring:   c.scoring(),
+		Platforms: c.Platforms,
+		Tags:      c.Tags,
+	}
+	for name, d := range defaultChecks {
+		enabled := c.Enabled(name)
+		cc := CheckConfig{
+			Enabled: &enabled,
+			Timeout: c.checkTimeout(name).String(),
+		}
+		if w, ok := weights[name]; ok {
+			cc.Weight = &w
+		}
+		if d.threshold != 0 {
+			t := c.Threshold(name)
+			cc.Threshold = &t
+		}
+		if len(d.thresholds) > 0 {
+			cc.Thresholds = c.Thresholds(name)
+		}
+		if d.exclude != nil {
+			cc.Exclude = c.Excludes(name)
+		}
+		if d.families != nil {
+			cc.Families = c.Families(name)
+		}
+		eff.Checks[name] = cc
+	}
+
+	return eff
+}
+
+// defaultExclude returns the patterns that are always excluded
+func defaultExclude() []string {
+	var patterns []string
+	patterns = append(patterns, skipDirs...)
+	for _, suffix := range skipSuffixes {
+		patterns = append(patterns, "*"+suffix)
+	}
+	sort.Strings(patterns)
+
+	return patterns
+}
+
+// excluded reports whether the file or directory at rel, relative
+// to the module root, matches one of the exclude patterns
+func excluded(patterns []string, rel string) bool {
+	return excludePattern(patterns, rel) != ""
+}
+
+// excludePattern returns the first of the exclude patterns that
+// matches the file or directory at rel, or "" if none does
+func excludePattern(patterns []string, rel string) string {
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(rel)
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = base
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return pattern
+		}
+	}
+
+	return ""
+}
+
+// weighted overrides the weight of a check
+type weighted struct {
+	Check
+	weight float64
+}
+
+// Weight returns the configured weight of the check
+func (w weighted) Weight() float64 {
+	return w.weight
+}
+
+// PercentageContext runs the check with ctx, if it supports it
+func (w weighted) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return percentage(ctx, w.Check)
+}
@@ -0,0 +1,142 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("testdata/configrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		enabled   bool
+		threshold int
+	}{
+		{"gofmt", false, 0},
+		{"go_vet", true, 0},
+		{"gocyclo", true, 5},
+		{"staticcheck", false, 0},
+	}
+	for _, tt := range cases {
+		if got := cfg.Enabled(tt.name); got != tt.enabled {
+			t.Errorf("Enabled(%q) = %t, want %t", tt.name, got, tt.enabled)
+		}
+		if got := cfg.Threshold(tt.name); got != tt.threshold {
+			t.Errorf("Threshold(%q) = %d, want %d", tt.name, got, tt.threshold)
+		}
+	}
+
+	cfg, err = LoadConfig("testdata/testfiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Config{}) {
+		t.Errorf("LoadConfig without a config file = %#v, want the zero Config", cfg)
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{"mocks", "*_gen.go", "internal/proto"}
+	cases := []struct {
+		rel  string
+		want bool
+	}{
+		{"mocks", true},
+		{"pkg/mocks", true},
+		{"a_gen.go", true},
+		{"pkg/a_gen.go", true},
+		{"internal/proto", true},
+		{"pkg/internal/proto", false},
+		{"a.go", false},
+	}
+
+	for _, tt := range cases {
+		if got := excluded(patterns, tt.rel); got != tt.want {
+			t.Errorf("excluded(%v, %q) = %t, want %t", patterns, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	cr, err := Run("testdata/configrepo", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cr.Files != 1 {
+		t.Errorf("got cr.Files = %d, want %d", cr.Files, 1)
+	}
+
+	for _, s := range cr.Checks {
+		if s.Name == "gofmt" {
+			t.Errorf("gofmt ran, but is disabled in the config")
+		}
+		if s.Name == "gocyclo" && (s.Weight != 0.5 || len(s.FileSummaries) != 1) {
+			t.Errorf("gocyclo has weight %f and %d file summaries, want 0.5 and 1", s.Weight, len(s.FileSummaries))
+		}
+	}
+
+	if cc := cr.Config.Checks["gocyclo"]; cc.Threshold == nil || *cc.Threshold != 5 {
+		t.Errorf("effective gocyclo threshold = %v, want 5", cc.Threshold)
+	}
+}
+
+func TestValidateTimeout(t *testing.T) {
+	cases := []struct {
+		timeout string
+		valid   bool
+	}{
+		{"", true},
+		{"90s", true},
+		{"5m", true},
+		{"0s", false},
+		{"-1m", false},
+		{"soon", false},
+	}
+	for _, tt := range cases {
+		cfg := Config{Timeout: tt.timeout}
+		if err := cfg.validate(); (err == nil) != tt.valid {
+			t.Errorf("validate() with timeout %q = %v, want valid = %t", tt.timeout, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateScoring(t *testing.T) {
+	cases := []struct {
+		scoring Scoring
+		valid   bool
+	}{
+		{"", true},
+		{ScoringFiles, true},
+		{ScoringDensity, true},
+		{"kloc", false},
+	}
+	for _, tt := range cases {
+		cfg := Config{Scoring: tt.scoring}
+		if err := cfg.validate(); (err == nil) != tt.valid {
+			t.Errorf("validate() with scoring %q = %v, want valid = %t", tt.scoring, err, tt.valid)
+		}
+	}
+}
+
+func TestExcludes(t *testing.T) {
+	cfg := Config{Checks: map[string]CheckConfig{
+		"errcheck": {Exclude: []string{"(*os.File).Close"}},
+	}}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]string{}, errcheckExclude...), "(*os.File).Close")
+	if got := cfg.Excludes("errcheck"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Excludes(%q) = %v, want %v", "errcheck", got, want)
+	}
+
+	cfg = Config{Checks: map[string]CheckConfig{"gofmt": {Exclude: []string{"fmt.Println"}}}}
+	if err := cfg.validate(); err == nil {
+		t.Errorf("validate() with an exclude list for gofmt = nil, want an error")
+	}
+}
@@ -0,0 +1,212 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Deprecated is the check for uses of identifiers that the standard
+// library or a dependency marks as deprecated, with a paragraph that
+// starts with "Deprecated:" in their doc comment
+type Deprecated struct {
+	Dir       string
+	Filenames []string
+	Driver    *Driver
+}
+
+// Name returns the name of the display name of the command
+func (d Deprecated) Name() string {
+	return "deprecated"
+}
+
+// Weight returns the weight this check has in the overall average
+func (d Deprecated) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the percentage of .go files
+// that do not use deprecated identifiers
+func (d Deprecated) Percentage() (float64, []FileSummary, error) {
+	return d.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (d Deprecated) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return Analyze(ctx, d.Driver, d.Filenames, deprecatedAnalyzer)
+}
+
+// deprecatedFact records the deprecation notice of an object,
+// so packages that use it can report it
+type deprecatedFact struct {
+	Notice string
+	// Anchor names the object in the documentation of its
+	// package, such as "Counter.Reset" for a method
+	Anchor string
+}
+
+// AFact marks deprecatedFact as an analysis fact
+func (*deprecatedFact) AFact() {}
+
+func (f *deprecatedFact) String() string {
+	return "deprecated: " + f.Notice
+}
+
+// deprecatedAnalyzer exports a fact for every deprecated object of a
+// package, and reports the uses of deprecated objects of other packages
+var deprecatedAnalyzer = &analysis.Analyzer{
+	Name:      "deprecated",
+	Doc:       "report uses of deprecated identifiers",
+	Run:       runDeprecated,
+	FactTypes: []analysis.Fact{new(deprecatedFact)},
+}
+
+func runDeprecated(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		exportDeprecated(pass, f)
+	}
+
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := origin(pass.TypesInfo.Uses[id])
+			// the package an identifier is declared in
+			// may still use it, and its tests too
+			if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() == pass.Pkg.Path() {
+				return true
+			}
+
+			var fact deprecatedFact
+			if !pass.ImportObjectFact(obj, &fact) {
+				return true
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     id.Pos(),
+				End:     id.End(),
+				Message: fmt.Sprintf("%s is deprecated: %s", objectName(obj, fact.Anchor), fact.Notice),
+				URL:     fmt.Sprintf("https://pkg.go.dev/%s#%s", obj.Pkg().Path(), fact.Anchor),
+			})
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+// exportDeprecated exports a fact for every object
+// declared in a file that is marked as deprecated
+func exportDeprecated(pass *analysis.Pass, f *ast.File) {
+	// owner is the type that methods and fields belong to
+	export := func(owner string, name *ast.Ident, docs ...*ast.CommentGroup) {
+		obj := pass.TypesInfo.Defs[name]
+		if obj == nil {
+			return
+		}
+		anchor := name.Name
+		if owner != "" {
+			anchor = owner + "." + anchor
+		}
+		for _, doc := range docs {
+			if notice := deprecationNotice(doc); notice != "" {
+				pass.ExportObjectFact(obj, &deprecatedFact{Notice: notice, Anchor: anchor})
+				return
+			}
+		}
+	}
+	fields := func(owner string, list *ast.FieldList) {
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				export(owner, name, field.Doc)
+			}
+		}
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			var owner string
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				owner = recvName(decl.Recv.List[0].Type)
+			}
+			export(owner, decl.Name, decl.Doc)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// the doc comment of an ungrouped declaration
+				// belongs to the declaration, not its spec
+				var doc *ast.CommentGroup
+				if !decl.Lparen.IsValid() {
+					doc = decl.Doc
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					export("", spec.Name, spec.Doc, doc)
+					switch t := spec.Type.(type) {
+					case *ast.StructType:
+						fields(spec.Name.Name, t.Fields)
+					case *ast.InterfaceType:
+						fields(spec.Name.Name, t.Methods)
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						export("", name, spec.Doc, doc)
+					}
+				}
+			}
+		}
+	}
+}
+
+// deprecationNotice returns the text of the paragraph of a doc comment
+// that starts with "Deprecated:", which says what to use instead
+func deprecationNotice(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	for _, p := range strings.Split(doc.Text(), "\n\n") {
+		if notice, ok := strings.CutPrefix(p, "Deprecated:"); ok {
+			return strings.Join(strings.Fields(notice), " ")
+		}
+	}
+
+	return ""
+}
+
+// origin returns the generic object an object of
+// an instantiated function or type was created from
+func origin(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Origin()
+	case *types.Var:
+		return obj.Origin()
+	}
+
+	return obj
+}
+
+// objectName returns the name of an object as its package's users
+// write it, such as "strings.Title", "(*rand.Rand).Seed" or, for a
+// field, "tls.Config.PreferServerCipherSuites"
+func objectName(obj types.Object, anchor string) string {
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Signature().Recv(); recv != nil {
+			qualifier := func(p *types.Package) string { return p.Name() }
+			return fmt.Sprintf("(%s).%s", types.TypeString(recv.Type(), qualifier), fn.Name())
+		}
+	}
+
+	return obj.Pkg().Name() + "." + anchor
+}
+
+// Description returns the description of Deprecated
+func (d Deprecated) Description() string {
+	return `Finds uses of identifiers that the standard library or a dependency marks as <a href="https://go.dev/wiki/Deprecated">deprecated</a>, such as <code>ioutil.ReadFile</code> or <code>strings.Title</code>, along with what their deprecation notice suggests instead.`
+}
@@ -0,0 +1,59 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	dir := "testdata/deprecatedrepo"
+	filenames, _, err := GoFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, fs, err := Deprecated{Dir: dir, Filenames: filenames, Driver: NewDriver(dir)}.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// main.go uses deprecated identifiers, but old.go's
+	// own use of Sum does not count
+	if want := .5; p != want {
+		t.Errorf("Percentage() = %f, want %f", p, want)
+	}
+
+	// like other checks, findings are scored against the size of the code
+	sc, err := newScoring(ScoringDensity, filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := sc.score(Deprecated{}, fs); err != nil || s != densityScore(sc.lines, fs) {
+		t.Errorf("density score = %f, %v, want %f", s, err, densityScore(sc.lines, fs))
+	}
+
+	type finding struct {
+		line   int
+		msg    string
+		docURL string
+	}
+	want := []finding{
+		{12, "warning: ioutil.ReadFile is deprecated: As of Go 1.16, this function simply calls [os.ReadFile]. (deprecated)", "https://pkg.go.dev/io/ioutil#ReadFile"},
+		{13, "warning: strings.Title is deprecated: The rule Title uses for word boundaries does not handle Unicode punctuation properly. Use golang.org/x/text/cases instead. (deprecated)", "https://pkg.go.dev/strings#Title"},
+		{15, "warning: old.Counter.Count is deprecated: Use N instead. (deprecated)", "https://pkg.go.dev/example.com/deprecatedrepo/old#Counter.Count"},
+		{15, "warning: old.Limit is deprecated: Counters have no limit. (deprecated)", "https://pkg.go.dev/example.com/deprecatedrepo/old#Limit"},
+		{16, "warning: (*old.Counter).Reset is deprecated: Assign a zero Counter instead. (deprecated)", "https://pkg.go.dev/example.com/deprecatedrepo/old#Counter.Reset"},
+		{17, "warning: old.Sum is deprecated: Use Add instead, which does not allocate. (deprecated)", "https://pkg.go.dev/example.com/deprecatedrepo/old#Sum"},
+	}
+	var got []finding
+	for _, f := range fs {
+		if f.Filename != dir+"/main.go" {
+			t.Errorf("finding in %s, want only main.go", f.Filename)
+		}
+		for _, e := range f.Errors {
+			got = append(got, finding{e.LineNumber, e.ErrorString, e.DocURL})
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DocCoverage is the check for doc comments on exported identifiers
+type DocCoverage struct {
+	Dir       string
+	Filenames []string
+
+	// items is what PercentageContext measured,
+	// which score scores findings against
+	items []docItem
+}
+
+// Name returns the name of the display name of the command
+func (d *DocCoverage) Name() string {
+	return "doc_coverage"
+}
+
+// Weight returns the weight this check has in the overall average
+func (d *DocCoverage) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the share of packages and exported
+// identifiers that have a doc comment
+func (d *DocCoverage) Percentage() (float64, []FileSummary, error) {
+	return d.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (d *DocCoverage) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	items, err := d.measure(ctx)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+	d.items = items
+
+	var failed = []FileSummary{}
+	index := make(map[string]int)
+	for _, it := range items {
+		if it.documented {
+			continue
+		}
+
+		filename := strings.TrimPrefix(it.filename, "_repos/src")
+		dfn := displayFilename(filename)
+		i, ok := index[dfn]
+		if !ok {
+			i = len(failed)
+			index[dfn] = i
+			failed = append(failed, FileSummary{Filename: dfn, FileURL: fileURL(filename)})
+		}
+		rule, msg := "missing-doc", fmt.Sprintf("warning: exported %s %s should have a doc comment (doc_coverage)", it.kind, it.name)
+		if it.kind == "package" {
+			rule, msg = "missing-package-doc", fmt.Sprintf("warning: package %s should have a package comment (doc_coverage)", it.name)
+		}
+		failed[i].Errors = append(failed[i].Errors, ruleError(rule, it.line, 0, msg))
+	}
+
+	p, err := d.score(d.Filenames, failed)
+	return p, failed, err
+}
+
+// score returns the share of documented identifiers,
+// counting the findings left in failed as undocumented
+func (d *DocCoverage) score(filenames []string, failed []FileSummary) (float64, error) {
+	var undocumented int
+	for _, fs := range failed {
+		undocumented += len(fs.Errors)
+	}
+
+	return ratio(len(d.items)-undocumented, len(d.items)), nil
+}
+
+// docItem is a package or exported identifier that should have a doc comment
+type docItem struct {
+	// kind is "package", "func", "method", "type", "const" or "var"
+	kind       string
+	name       string
+	filename   string
+	line       int
+	documented bool
+}
+
+// measure lists the packages and exported identifiers in the
+// non-test files of the check, and whether they are documented.
+// Files that do not parse are left out.
+func (d *DocCoverage) measure(ctx context.Context) ([]docItem, error) {
+	type pkg struct {
+		dir, name string
+	}
+	var pkgs []pkg
+	pkgItems := make(map[pkg]docItem)
+
+	var items []docItem
+	for _, fn := range d.Filenames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, fn, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+
+		// a package is documented if any of its files has a package comment
+		p := pkg{filepath.Dir(fn), f.Name.Name}
+		pi, ok := pkgItems[p]
+		if !ok {
+			pkgs = append(pkgs, p)
+			pi = docItem{kind: "package", name: f.Name.Name, filename: fn, line: fset.Position(f.Package).Line}
+		}
+		pi.documented = pi.documented || f.Doc != nil
+		pkgItems[p] = pi
+
+		// exported identifiers of commands are not API
+		if f.Name.Name == "main" {
+			continue
+		}
+		items = append(items, fileDocItems(fset, fn, f)...)
+	}
+
+	for _, p := range pkgs {
+		items = append(items, pkgItems[p])
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].filename != items[j].filename {
+			return items[i].filename < items[j].filename
+		}
+		return items[i].line < items[j].line
+	})
+
+	return items, nil
+}
+
+// fileDocItems lists the exported identifiers declared in a file
+func fileDocItems(fset *token.FileSet, filename string, f *ast.File) []docItem {
+	var items []docItem
+	add := func(kind, name string, pos token.Pos, doc ...*ast.CommentGroup) {
+		documented := false
+		for _, cg := range doc {
+			documented = documented || cg != nil
+		}
+		items = append(items, docItem{
+			kind:       kind,
+			name:       name,
+			filename:   filename,
+			line:       fset.Position(pos).Line,
+			documented: documented,
+		})
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() || !exportedRecv(decl) {
+				continue
+			}
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				add("method", recvName(decl.Recv.List[0].Type)+"."+decl.Name.Name, decl.Name.Pos(), decl.Doc)
+				continue
+			}
+			add("func", decl.Name.Name, decl.Name.Pos(), decl.Doc)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						add("type", spec.Name.Name, spec.Name.Pos(), decl.Doc, spec.Doc)
+					}
+				case *ast.ValueSpec:
+					// a comment on the group or the spec documents every
+					// name in the spec, and one at the end of the line
+					// does not, as in go/doc
+					for _, id := range spec.Names {
+						if id.IsExported() {
+							add(decl.Tok.String(), id.Name, id.Pos(), decl.Doc, spec.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// Description returns the description of DocCoverage
+func (d *DocCoverage) Description() string {
+	return `Measures the share of packages with a package comment and of exported types, functions, methods, constants and variables with a <a href="https://go.dev/doc/comment">doc comment</a>.`
+}
@@ -0,0 +1,47 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocCoverage(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/docrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DocCoverage{Dir: "testdata/docrepo", Filenames: filenames}
+	p, summaries, err := d.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 packages and 11 exported identifiers, of which the
+	// package cmd and 6 identifiers are undocumented
+	if want := 6.0 / 13.0; p != want {
+		t.Errorf("Percentage() = %f, want %f", p, want)
+	}
+
+	got := make(map[string][]int)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			got[fs.Filename] = append(got[fs.Filename], e.LineNumber)
+		}
+	}
+	want := map[string][]int{
+		"testdata/docrepo/a.go":        {7, 14, 29, 30, 33},
+		"testdata/docrepo/b.go":        {3},
+		"testdata/docrepo/cmd/main.go": {1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines of findings = %v, want %v", got, want)
+	}
+
+	// findings left after suppressions are scored against
+	// the identifiers Percentage measured
+	d.Filenames = nil
+	if s, err := d.score(filenames, summaries[1:]); err != nil || s != 11.0/13.0 {
+		t.Errorf("score() without the findings of %s = %f, %v, want %f", summaries[0].Filename, s, err, 11.0/13.0)
+	}
+}
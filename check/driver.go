@@ -0,0 +1,477 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic is a single finding reported by an analyzer
+type Diagnostic struct {
+	Analyzer string
+	Filename string
+	Line     int
+	Column   int
+	// EndLine and EndColumn are zero if the
+	// analyzer reported a position, not a range
+	EndLine   int
+	EndColumn int
+	Message   string
+	// URL links to the documentation of the diagnostic
+	URL string
+	// Targets lists the targets the diagnostic was reported for,
+	// if it was not reported for all of the driver's targets
+	Targets []string
+}
+
+// key identifies a diagnostic reported for more than one target
+func (d Diagnostic) key() string {
+	return fmt.Sprintf("%s:%d:%d:%d:%d: %s (%s)", d.Filename, d.Line, d.Column, d.EndLine, d.EndColumn, d.Message, d.Analyzer)
+}
+
+// Driver loads the packages in a directory once and runs
+// go/analysis analyzers over them in-process, so that all
+// checks share the cost of parsing and type-checking
+type Driver struct {
+	Dir string
+	// Targets are the platforms and build tags packages are loaded
+	// for, once each. Without targets packages are loaded for the
+	// host platform without build tags.
+	Targets []Target
+	// Context bounds loading the packages, which all checks share,
+	// so it is the context of the whole run rather than of any one
+	// check. Packages load without a deadline if it is not set.
+	Context context.Context
+
+	once  sync.Once
+	done  chan struct{}
+	loads []load
+	err   error
+}
+
+// load is the packages loaded for a target
+type load struct {
+	target Target
+	pkgs   []*packages.Package
+}
+
+// NewDriver returns a Driver for the packages in dir,
+// loaded for each of targets
+func NewDriver(dir string, targets ...Target) *Driver {
+	return &Driver{Dir: dir, Targets: targets}
+}
+
+// targets returns the targets packages are loaded for
+func (d *Driver) targets() []Target {
+	if len(d.Targets) == 0 {
+		return []Target{hostTarget()}
+	}
+
+	return d.Targets
+}
+
+// Packages returns the packages in the driver's directory, loading
+// them on first use. Packages are loaded for all targets in parallel,
+// and returned for the first target they could be loaded for. If ctx
+// is done before they are loaded, Packages returns ctx.Err() and
+// leaves them to load for the other callers.
+func (d *Driver) Packages(ctx context.Context) ([]*packages.Package, error) {
+	if err := d.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return d.loads[0].pkgs, nil
+}
+
+// wait starts loading the packages on first use, under the driver's
+// context, and waits until they are loaded or ctx is done
+func (d *Driver) wait(ctx context.Context) error {
+	d.once.Do(func() {
+		d.done = make(chan struct{})
+		loadCtx := d.Context
+		if loadCtx == nil {
+			loadCtx = context.Background()
+		}
+		go func() {
+			defer close(d.done)
+			d.loadAll(loadCtx)
+		}()
+	})
+
+	select {
+	case <-d.done:
+		return d.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loadAll loads the packages for every target. Targets that fail to
+// load are left out, and the driver fails only if none of them load.
+// Targets whose packages do not type-check are left out as well,
+// unless none of them do, in which case the first one is kept.
+func (d *Driver) loadAll(ctx context.Context) {
+	targets := d.targets()
+	loads := make([]load, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			loads[i].target = t
+			loads[i].pkgs, errs[i] = d.loadTarget(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var illTyped []load
+	for i := range loads {
+		if errs[i] != nil {
+			if len(targets) > 1 {
+				log.Printf("WARNING: %s: %v", targets[i], errs[i])
+			}
+			if d.err == nil {
+				d.err = errs[i]
+			}
+			continue
+		}
+		if len(targets) > 1 && typeError(loads[i].pkgs) != nil {
+			illTyped = append(illTyped, loads[i])
+			continue
+		}
+		d.loads = append(d.loads, loads[i])
+	}
+	if len(d.loads) == 0 && len(illTyped) > 0 {
+		d.loads, illTyped = illTyped[:1], illTyped[1:]
+	}
+	for _, l := range illTyped {
+		log.Printf("WARNING: %s: left out, does not type-check: %v", l.target, typeError(l.pkgs))
+	}
+	if len(d.loads) > 0 {
+		d.err = nil
+	}
+}
+
+// typeError returns the first type error of the packages, if any
+func typeError(pkgs []*packages.Package) error {
+	for _, pkg := range pkgs {
+		if len(pkg.TypeErrors) > 0 {
+			return pkg.TypeErrors[0]
+		}
+	}
+
+	return nil
+}
+
+// loadTarget loads the packages in the driver's directory for a target
+func (d *Driver) loadTarget(ctx context.Context, t Target) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Context:    ctx,
+		Mode:       packages.LoadAllSyntax,
+		Dir:        d.Dir,
+		Tests:      true,
+		Env:        append(os.Environ(), t.env()...),
+		BuildFlags: t.buildFlags(),
+	}
+	if !moduleMode(d.Dir) {
+		cfg.Env = append(cfg.Env, "GO111MODULE=off", "GOFLAGS=")
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages: %v", err)
+	}
+
+	var loaded []*packages.Package
+	for _, pkg := range pkgs {
+		// skip the generated main packages of test binaries
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		}
+		loaded = append(loaded, pkg)
+	}
+	if len(loaded) == 0 {
+		return nil, &SkipError{Reason: fmt.Sprintf("no packages found in %s", d.Dir)}
+	}
+
+	return loaded, nil
+}
+
+// Run runs the analyzers over the driver's packages for every target
+// and returns their diagnostics sorted by position. Diagnostics that
+// are reported for some of the targets only list the ones they were
+// reported for. If ctx is done first, Run returns ctx.Err() and the
+// analyzers stop before the next package they would analyze.
+func (d *Driver) Run(ctx context.Context, analyzers ...*analysis.Analyzer) ([]Diagnostic, error) {
+	if err := d.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		diags []Diagnostic
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		diags, err := d.analyze(ctx, analyzers)
+		done <- result{diags, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.diags, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// analyze runs the analyzers for every target the
+// driver loaded packages for, and merges their diagnostics
+func (d *Driver) analyze(ctx context.Context, analyzers []*analysis.Analyzer) ([]Diagnostic, error) {
+	analyzers = withContext(ctx, analyzers)
+	graphs := make([]*checker.Graph, len(d.loads))
+	errs := make([]error, len(d.loads))
+	var wg sync.WaitGroup
+	for i, l := range d.loads {
+		wg.Add(1)
+		go func(i int, l load) {
+			defer wg.Done()
+			if errs[i] = ctx.Err(); errs[i] != nil {
+				return
+			}
+			graphs[i], errs[i] = checker.Analyze(analyzers, l.pkgs, nil)
+		}(i, l)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// packages compiled both with and without their tests, and
+	// for more than one target, report the same diagnostics twice
+	index := make(map[string]int)
+	var diags []Diagnostic
+	for i, graph := range graphs {
+		target := d.loads[i].target.String()
+		for _, act := range graph.Roots {
+			if act.Err != nil {
+				log.Printf("WARNING: (%s) %s: %v", act.Analyzer.Name, act.Package.ID, act.Err)
+				continue
+			}
+
+			for _, ad := range act.Diagnostics {
+				diag := newDiagnostic(act.Analyzer, act.Package.Fset, ad)
+				k := diag.key()
+				j, ok := index[k]
+				if !ok {
+					j = len(diags)
+					index[k] = j
+					diags = append(diags, diag)
+				}
+				if !contains(diags[j].Targets, target) {
+					diags[j].Targets = append(diags[j].Targets, target)
+				}
+			}
+		}
+	}
+
+	for i := range diags {
+		if len(diags[i].Targets) == len(d.loads) {
+			diags[i].Targets = nil
+		}
+	}
+	sortDiagnostics(diags)
+
+	return diags, nil
+}
+
+// withContext returns copies of the analyzers, and of the analyzers they
+// require, that fail with ctx.Err() instead of analyzing a package once
+// ctx is done. The copies run the original analyzers, with the results
+// of the analyzers they require keyed by the originals.
+func withContext(ctx context.Context, analyzers []*analysis.Analyzer) []*analysis.Analyzer {
+	copies := make(map[*analysis.Analyzer]*analysis.Analyzer)
+	var copyOf func(a *analysis.Analyzer) *analysis.Analyzer
+	copyOf = func(a *analysis.Analyzer) *analysis.Analyzer {
+		if c, ok := copies[a]; ok {
+			return c
+		}
+
+		c := *a
+		copies[a] = &c
+		c.Requires = make([]*analysis.Analyzer, len(a.Requires))
+		for i, req := range a.Requires {
+			c.Requires[i] = copyOf(req)
+		}
+		c.Run = func(pass *analysis.Pass) (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+			for i, req := range a.Requires {
+				resultOf[req] = pass.ResultOf[c.Requires[i]]
+			}
+			pass.Analyzer, pass.ResultOf = a, resultOf
+
+			return a.Run(pass)
+		}
+
+		return &c
+	}
+
+	wrapped := make([]*analysis.Analyzer, len(analyzers))
+	for i, a := range analyzers {
+		wrapped[i] = copyOf(a)
+	}
+
+	return wrapped
+}
+
+// newDiagnostic converts a diagnostic reported by an analyzer
+func newDiagnostic(a *analysis.Analyzer, fset *token.FileSet, ad analysis.Diagnostic) Diagnostic {
+	pos := fset.Position(ad.Pos)
+	diag := Diagnostic{
+		Analyzer: a.Name,
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Message:  ad.Message,
+		URL:      ad.URL,
+	}
+	if ad.End.IsValid() && ad.End > ad.Pos {
+		end := fset.Position(ad.End)
+		diag.EndLine, diag.EndColumn = end.Line, end.Column
+	}
+	if diag.URL == "" {
+		diag.URL = a.URL
+	}
+
+	return diag
+}
+
+// sortDiagnostics sorts diagnostics by position
+func sortDiagnostics(diags []Diagnostic) {
+	sort.Slice(diags, func(i, j int) bool {
+		if diags[i].Filename != diags[j].Filename {
+			return diags[i].Filename < diags[j].Filename
+		}
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+}
+
+// moduleMode reports whether the packages in dir can be loaded in module
+// mode. Repositories without a go.mod file, or checked out below a directory
+// that is not a valid import path element (such as "repo@v0.1.0"), are loaded
+// in GOPATH mode instead.
+func moduleMode(dir string) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(abs, "go.mod")); err == nil {
+			return true
+		}
+		if strings.Contains(filepath.Base(abs), "@") {
+			return false
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return false
+		}
+		abs = parent
+	}
+}
+
+// Analyze runs the analyzers on the driver's packages and scores the
+// diagnostics reported for filenames the same way GoTool does
+func Analyze(ctx context.Context, d *Driver, filenames []string, analyzers ...*analysis.Analyzer) (float64, []FileSummary, error) {
+	diags, err := d.Run(ctx, analyzers...)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	return summarize(filenames, diags)
+}
+
+// AnalyzeFiles is like Analyze for analyzers that only look at the
+// syntax of files. It does not load packages, and analyzes shards
+// of the files in parallel.
+func AnalyzeFiles(ctx context.Context, filenames []string, analyzers ...*analysis.Analyzer) (float64, []FileSummary, error) {
+	diags, err := runSyntactic(ctx, filenames, analyzers...)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	return summarize(filenames, diags)
+}
+
+// summarize scores the diagnostics reported for filenames
+func summarize(filenames []string, diags []Diagnostic) (float64, []FileSummary, error) {
+	// diagnostics can be reported with absolute paths, so map
+	// them back to the filenames we were asked about
+	names := make(map[string]string, len(filenames))
+	for _, fn := range filenames {
+		abs, err := filepath.Abs(fn)
+		if err != nil {
+			return 0, []FileSummary{}, err
+		}
+		names[abs] = fn
+	}
+
+	var failed = []FileSummary{}
+	index := make(map[string]int)
+	for _, diag := range diags {
+		abs, err := filepath.Abs(diag.Filename)
+		if err != nil {
+			return 0, []FileSummary{}, err
+		}
+		filename, ok := names[abs]
+		if !ok {
+			continue
+		}
+
+		filename = strings.TrimPrefix(filename, "_repos/src")
+		dfn := displayFilename(filename)
+		i, ok := index[dfn]
+		if !ok {
+			i = len(failed)
+			index[dfn] = i
+			failed = append(failed, FileSummary{Filename: dfn, FileURL: fileURL(filename)})
+		}
+		e := ruleError(diag.Analyzer, diag.Line, diag.Column, fmt.Sprintf("warning: %s (%s)", diag.Message, diag.Analyzer))
+		e.EndLine, e.EndColumn = diag.EndLine, diag.EndColumn
+		e.Targets = diag.Targets
+		if e.DocURL == "" {
+			e.DocURL = diag.URL
+		}
+		failed[i].Errors = append(failed[i].Errors, e)
+	}
+
+	p, err := passRatio(filenames, failed)
+	if err != nil {
+		return 0, failed, err
+	}
+
+	return p, failed, nil
+}
@@ -0,0 +1,69 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+func TestDriverCallerContext(t *testing.T) {
+	d := NewDriver("testdata/testsrepo")
+
+	// a caller that gives up does not fail the load for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.Packages(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Packages() with a canceled context = %v, want %v", err, context.Canceled)
+	}
+
+	pkgs, err := d.Packages(context.Background())
+	if err != nil {
+		t.Fatalf("Packages() after another caller gave up = %v, want no error", err)
+	}
+	if len(pkgs) == 0 {
+		t.Errorf("Packages() returned no packages")
+	}
+}
+
+func TestDriverAnalyzeContext(t *testing.T) {
+	d := NewDriver("testdata/testsrepo")
+	if _, err := d.Packages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var runs atomic.Int32
+	a := &analysis.Analyzer{
+		Name:     "count",
+		Doc:      "count the packages analyzed",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			if pass.ResultOf[inspect.Analyzer] == nil {
+				t.Errorf("ResultOf[inspect.Analyzer] = nil, want the result of the inspect analyzer")
+			}
+			runs.Add(1)
+			return nil, nil
+		},
+	}
+
+	if _, err := d.analyze(context.Background(), []*analysis.Analyzer{a}); err != nil {
+		t.Fatal(err)
+	}
+	if runs.Load() == 0 {
+		t.Errorf("analyze() ran no passes, want one for every package")
+	}
+
+	// the analyzers stop once the context is done
+	runs.Store(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.analyze(ctx, []*analysis.Analyzer{a}); !errors.Is(err, context.Canceled) {
+		t.Errorf("analyze() with a canceled context = %v, want %v", err, context.Canceled)
+	}
+	if n := runs.Load(); n != 0 {
+		t.Errorf("analyze() with a canceled context ran %d passes, want 0", n)
+	}
+}
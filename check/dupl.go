@@ -0,0 +1,372 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// duplOver is the default number of tokens above
+// which duplicated code is reported
+const duplOver = 100
+
+// Dupl is the check for duplicated code. It compares the token
+// sequences of all files, with identifiers and literals reduced to
+// their kind, so copies whose names or values were changed are found
+// as well.
+type Dupl struct {
+	Dir       string
+	Filenames []string
+	// Over is the number of tokens above which duplicated
+	// code is reported, duplOver if it is not set
+	Over int
+
+	// files and groups are what PercentageContext measured,
+	// which score scores findings against
+	files  []duplFile
+	groups []cloneGroup
+}
+
+func (d *Dupl) over() int {
+	if d.Over == 0 {
+		return duplOver
+	}
+
+	return d.Over
+}
+
+// Name returns the name of the display name of the command
+func (d *Dupl) Name() string {
+	return "dupl"
+}
+
+// Weight returns the weight this check has in the overall average
+func (d *Dupl) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the share of tokens of the .go
+// files that are not part of duplicated code
+func (d *Dupl) Percentage() (float64, []FileSummary, error) {
+	return d.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (d *Dupl) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	files, groups, err := d.measure(ctx)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+	d.files, d.groups = files, groups
+
+	var failed = []FileSummary{}
+	index := make(map[string]int)
+	for _, g := range groups {
+		locs := make([]Location, len(g.clones))
+		for i, c := range g.clones {
+			locs[i] = files[c.file].location(c)
+		}
+
+		for i, loc := range locs {
+			var others []string
+			related := make([]Location, 0, len(locs)-1)
+			for j, other := range locs {
+				if j != i {
+					others = append(others, fmt.Sprintf("%s:%d-%d", other.Filename, other.LineNumber, other.EndLine))
+					related = append(related, other)
+				}
+			}
+
+			e := ruleError("duplicate", loc.LineNumber, 0, fmt.Sprintf("warning: %d lines (%d tokens) are duplicated in %s (dupl)", loc.EndLine-loc.LineNumber+1, g.length, strings.Join(others, ", ")))
+			e.EndLine = loc.EndLine
+			e.Related = related
+
+			k, ok := index[loc.Filename]
+			if !ok {
+				k = len(failed)
+				index[loc.Filename] = k
+				failed = append(failed, FileSummary{Filename: loc.Filename, FileURL: loc.FileURL})
+			}
+			failed[k].Errors = append(failed[k].Errors, e)
+		}
+	}
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].Filename < failed[j].Filename
+	})
+	for _, fs := range failed {
+		sort.SliceStable(fs.Errors, func(i, j int) bool {
+			return fs.Errors[i].LineNumber < fs.Errors[j].LineNumber
+		})
+	}
+
+	return duplScore(files, groups, failed), failed, nil
+}
+
+// score returns the share of tokens that are not part of the
+// duplicated code that findings are left for in failed
+func (d *Dupl) score(filenames []string, failed []FileSummary) (float64, error) {
+	return duplScore(d.files, d.groups, failed), nil
+}
+
+// duplScore returns the share of tokens of files that are not
+// part of the copies of groups that findings are left for in failed
+func duplScore(files []duplFile, groups []cloneGroup, failed []FileSummary) float64 {
+	left := make(map[string]bool)
+	for _, fs := range failed {
+		for _, e := range fs.Errors {
+			left[fmt.Sprintf("%s:%d", fs.Filename, e.LineNumber)] = true
+		}
+	}
+
+	var total int
+	covered := make([][]bool, len(files))
+	for i, f := range files {
+		total += len(f.tokens)
+		covered[i] = make([]bool, len(f.tokens))
+	}
+	for _, g := range groups {
+		for _, c := range g.clones {
+			loc := files[c.file].location(c)
+			if !left[fmt.Sprintf("%s:%d", loc.Filename, loc.LineNumber)] {
+				continue
+			}
+			for i := c.start; i < c.start+g.length; i++ {
+				covered[c.file][i] = true
+			}
+		}
+	}
+
+	var duplicated int
+	for _, cov := range covered {
+		for _, c := range cov {
+			if c {
+				duplicated++
+			}
+		}
+	}
+
+	return ratio(total-duplicated, total)
+}
+
+// duplToken is a token of a file, reduced to what
+// makes code a copy of other code
+type duplToken struct {
+	kind string
+	line int
+}
+
+// duplFile is the token sequence of a file
+type duplFile struct {
+	name   string
+	tokens []duplToken
+}
+
+// location returns the lines a clone covers
+func (f duplFile) location(c clone) Location {
+	filename := strings.TrimPrefix(f.name, "_repos/src")
+	return Location{
+		Filename:   displayFilename(filename),
+		FileURL:    fileURL(filename),
+		LineNumber: f.tokens[c.start].line,
+		EndLine:    f.tokens[c.start+c.length-1].line,
+	}
+}
+
+// clone is a copy of duplicated code, starting at
+// token start of file and length tokens long
+type clone struct {
+	file, start, length int
+}
+
+// cloneGroup is code that is duplicated in all of its clones
+type cloneGroup struct {
+	length int
+	clones []clone
+}
+
+// measure reads the tokens of the files and finds the groups of
+// identical sequences of more tokens than the threshold
+func (d *Dupl) measure(ctx context.Context) ([]duplFile, []cloneGroup, error) {
+	// imports are left out, since files often import the same packages
+	var files []duplFile
+	err := eachFile(ctx, d.Filenames, parser.ImportsOnly, func(fn string, src []byte, fset *token.FileSet, f *ast.File) error {
+		files = append(files, duplFile{name: fn, tokens: duplTokens(fset, f, src)})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, findClones(files, d.over()+1), nil
+}
+
+// duplTokens returns the tokens of a file after its imports, with
+// identifiers and literals reduced to their kind. The file is parsed
+// up to its imports only, into fset.
+func duplTokens(fset *token.FileSet, f *ast.File, src []byte) []duplToken {
+	start := fset.Position(f.Name.End()).Offset
+	if len(f.Decls) > 0 {
+		start = fset.Position(f.Decls[len(f.Decls)-1].End()).Offset
+	}
+
+	var s scanner.Scanner
+	file := fset.AddFile(fset.File(f.Pos()).Name(), -1, len(src))
+	s.Init(file, src, nil, 0)
+
+	var tokens []duplToken
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if file.Offset(pos) < start {
+			continue
+		}
+		// semicolons inserted at newlines depend on the layout
+		// of the code rather than on what it does
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		// identifiers and literals are kept as their kind only,
+		// which is what tok.String() returns for them
+		tokens = append(tokens, duplToken{kind: tok.String(), line: file.Line(pos)})
+	}
+
+	return tokens
+}
+
+// findClones returns the groups of identical token sequences that are
+// at least size tokens long, extended as far as all their copies agree
+func findClones(files []duplFile, size int) []cloneGroup {
+	type pos struct{ file, start int }
+
+	// windows of size tokens, by hash
+	const base = 1099511628211
+	buckets := make(map[uint64][]pos)
+	kindHash := func(kind string) uint64 {
+		var h uint64 = 14695981039346656037
+		for i := 0; i < len(kind); i++ {
+			h ^= uint64(kind[i])
+			h *= base
+		}
+		return h
+	}
+	var pow uint64 = 1
+	for i := 0; i < size-1; i++ {
+		pow *= base
+	}
+	for fi, f := range files {
+		if len(f.tokens) < size {
+			continue
+		}
+		var h uint64
+		for i, t := range f.tokens {
+			h = h*base + kindHash(t.kind)
+			if i >= size {
+				h -= kindHash(f.tokens[i-size].kind) * pow * base
+			}
+			if i >= size-1 {
+				buckets[h] = append(buckets[h], pos{fi, i - size + 1})
+			}
+		}
+	}
+
+	same := func(a, b pos, n int) bool {
+		ta, tb := files[a.file].tokens, files[b.file].tokens
+		if a.start+n > len(ta) || b.start+n > len(tb) {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			if ta[a.start+i].kind != tb[b.start+i].kind {
+				return false
+			}
+		}
+		return true
+	}
+
+	var groups []cloneGroup
+	keys := make([]uint64, 0, len(buckets))
+	for h, ps := range buckets {
+		if len(ps) > 1 {
+			keys = append(keys, h)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := buckets[keys[i]][0], buckets[keys[j]][0]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		return a.start < b.start
+	})
+
+	for _, h := range keys {
+		// the copies of the first window in the bucket that do not
+		// overlap each other, leaving out hash collisions
+		var ps []pos
+		for _, p := range buckets[h] {
+			if !same(buckets[h][0], p, size) {
+				continue
+			}
+			if n := len(ps); n > 0 && ps[n-1].file == p.file && ps[n-1].start+size > p.start {
+				continue
+			}
+			ps = append(ps, p)
+		}
+		if len(ps) < 2 {
+			continue
+		}
+
+		// windows whose copies all agree on the token before
+		// them are part of the group that starts there
+		extends := true
+		for _, p := range ps {
+			if p.start == 0 || files[p.file].tokens[p.start-1].kind != files[ps[0].file].tokens[ps[0].start-1].kind {
+				extends = false
+				break
+			}
+		}
+		if extends {
+			continue
+		}
+
+		// extend the copies a token at a time, as far as they all
+		// agree without running into the next copy in the same file
+		n := size
+		first := files[ps[0].file].tokens
+		for {
+			ok := true
+			for i, p := range ps {
+				tokens := files[p.file].tokens
+				if p.start+n >= len(tokens) || tokens[p.start+n].kind != first[ps[0].start+n].kind ||
+					i+1 < len(ps) && ps[i+1].file == p.file && p.start+n+1 > ps[i+1].start {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				break
+			}
+			n++
+		}
+
+		g := cloneGroup{length: n}
+		for _, p := range ps {
+			g.clones = append(g.clones, clone{file: p.file, start: p.start, length: n})
+		}
+		groups = append(groups, g)
+	}
+
+	return groups
+}
+
+// Description returns the description of Dupl
+func (d *Dupl) Description() string {
+	return fmt.Sprintf(`Finds code that is duplicated in sequences of more than %d tokens, across all files, even if identifiers or literals were changed in the copies. The score is the share of the code that is not duplicated.`, d.over())
+}
@@ -0,0 +1,62 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDupl(t *testing.T) {
+	d := &Dupl{Dir: "testdata/dupl", Filenames: []string{"testdata/dupl/a.go", "testdata/dupl/b.go"}, Over: 20}
+	p, fs, err := d.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 96 of the 128 tokens after the imports are duplicated
+	if want := 32.0 / 128.0; p != want {
+		t.Errorf("Dupl percent = %f, want %f", p, want)
+	}
+
+	want := []struct {
+		filename string
+		line     int
+		end      int
+		msg      string
+		related  []Location
+	}{
+		{"testdata/dupl/a.go", 6, 18, "warning: 13 lines (48 tokens) are duplicated in testdata/dupl/b.go:9-21 (dupl)", []Location{{Filename: "testdata/dupl/b.go", LineNumber: 9, EndLine: 21}}},
+		{"testdata/dupl/b.go", 9, 21, "warning: 13 lines (48 tokens) are duplicated in testdata/dupl/a.go:6-18 (dupl)", []Location{{Filename: "testdata/dupl/a.go", LineNumber: 6, EndLine: 18}}},
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("Dupl failed = %#v, want %d files", fs, len(want))
+	}
+	for i, w := range want {
+		if len(fs[i].Errors) != 1 {
+			t.Fatalf("%s: got %d errors, want 1", fs[i].Filename, len(fs[i].Errors))
+		}
+		e := fs[i].Errors[0]
+		if fs[i].Filename != w.filename || e.LineNumber != w.line || e.EndLine != w.end {
+			t.Errorf("finding %d = %s:%d-%d, want %s:%d-%d", i, fs[i].Filename, e.LineNumber, e.EndLine, w.filename, w.line, w.end)
+		}
+		if e.ErrorString != w.msg {
+			t.Errorf("error = %q, want %q", e.ErrorString, w.msg)
+		}
+		if !reflect.DeepEqual(e.Related, w.related) {
+			t.Errorf("related = %+v, want %+v", e.Related, w.related)
+		}
+	}
+
+	// findings left after suppressions are scored against
+	// the clones Percentage found, without reading the files
+	filenames := d.Filenames
+	d.Filenames = nil
+	if s, err := d.score(filenames, fs[1:]); err != nil || s != 80.0/128.0 {
+		t.Errorf("score() without the findings of %s = %f, %v, want %f", fs[0].Filename, s, err, 80.0/128.0)
+	}
+
+	d.Filenames = filenames
+	d.Over = 50
+	if p, fs, err := d.Percentage(); err != nil || p != 1 || len(fs) != 0 {
+		t.Errorf("Dupl with Over 50 = %f, %v, %v, want 1 and no findings", p, fs, err)
+	}
+}
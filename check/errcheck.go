@@ -1,9 +1,48 @@
 package check
 
-// ErrCheck is the check for the errcheck command
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errcheckExclude lists the functions whose errors are not
+// worth checking, because they are only returned to satisfy
+// an interface or are rarely handled in practice
+var errcheckExclude = []string{
+	"fmt.Print",
+	"fmt.Printf",
+	"fmt.Println",
+	"(*bytes.Buffer).Write",
+	"(*bytes.Buffer).WriteByte",
+	"(*bytes.Buffer).WriteRune",
+	"(*bytes.Buffer).WriteString",
+	"(*strings.Builder).Write",
+	"(*strings.Builder).WriteByte",
+	"(*strings.Builder).WriteRune",
+	"(*strings.Builder).WriteString",
+	"math/rand.Read",
+	"(*math/rand.Rand).Read",
+}
+
+// ErrCheck is the check for unchecked errors. It type-checks the
+// packages with the driver, and reports calls whose error results
+// are dropped, in statements of their own or in go and defer
+// statements.
 type ErrCheck struct {
 	Dir       string
 	Filenames []string
+	Driver    *Driver
+	// Exclude lists the functions whose errors may be dropped, by
+	// their full name such as "fmt.Println" or "(*os.File).Close",
+	// errcheckExclude if it is not set
+	Exclude []string
 }
 
 // Name returns the name of the display name of the command
@@ -16,12 +55,102 @@ func (c ErrCheck) Weight() float64 {
 	return .15
 }
 
-// Percentage returns the percentage of .go files that pass gofmt
+// Percentage returns the percentage of .go files without unchecked errors
 func (c ErrCheck) Percentage() (float64, []FileSummary, error) {
-	return GoTool(c.Dir, c.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--linter='errch:errcheck {path}:PATH:LINE:MESSAGE'", "--enable=errch"})
+	return c.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (c ErrCheck) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return Analyze(ctx, c.Driver, c.Filenames, c.analyzer())
+}
+
+// analyzer returns the analyzer that reports unchecked
+// errors, leaving out calls of the excluded functions
+func (c ErrCheck) analyzer() *analysis.Analyzer {
+	names := c.Exclude
+	if names == nil {
+		names = errcheckExclude
+	}
+	exclude := make(map[string]bool)
+	for _, name := range names {
+		exclude[name] = true
+	}
+
+	return &analysis.Analyzer{
+		Name:     "errcheck",
+		Doc:      "report calls whose error results are not checked",
+		URL:      "https://github.com/kisielk/errcheck",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return nil, runErrcheck(pass, exclude)
+		},
+	}
 }
 
-// Description returns the description of gofmt
+// runErrcheck reports the calls in statements of their own, and in
+// go and defer statements, that return an error which is dropped
+func runErrcheck(pass *analysis.Pass, exclude map[string]bool) error {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{(*ast.ExprStmt)(nil), (*ast.GoStmt)(nil), (*ast.DeferStmt)(nil)}
+	ins.Preorder(filter, func(n ast.Node) {
+		var call *ast.CallExpr
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			call, _ = ast.Unparen(n.X).(*ast.CallExpr)
+		case *ast.GoStmt:
+			call = n.Call
+		case *ast.DeferStmt:
+			call = n.Call
+		}
+		if call == nil || !returnsError(pass.TypesInfo, call) {
+			return
+		}
+
+		name := types.ExprString(call.Fun)
+		if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok {
+			name = fn.FullName()
+			if exclude[name] {
+				return
+			}
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("error returned by %s is not checked", name),
+		})
+	})
+
+	return nil
+}
+
+// returnsError reports whether one of the results of a call is an error
+func returnsError(info *types.Info, call *ast.CallExpr) bool {
+	tv, ok := info.Types[call]
+	if !ok || !tv.IsValue() {
+		return false
+	}
+
+	results := []types.Type{tv.Type}
+	if tuple, ok := tv.Type.(*types.Tuple); ok {
+		results = results[:0]
+		for i := 0; i < tuple.Len(); i++ {
+			results = append(results, tuple.At(i).Type())
+		}
+	}
+	for _, t := range results {
+		if types.Identical(t, errorType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errorType is the predeclared error interface
+var errorType = types.Universe.Lookup("error").Type()
+
+// Description returns the description of ErrCheck
 func (c ErrCheck) Description() string {
-	return `<a href="https://github.com/kisielk/errcheck">errcheck</a> finds unchecked errors in go programs`
+	return `Finds unchecked errors in Go programs, like <a href="https://github.com/kisielk/errcheck">errcheck</a>: calls whose error results are dropped, in statements of their own or in <code>go</code> and <code>defer</code> statements. Calls of functions such as <code>fmt.Println</code> are not reported, and more functions can be excluded in the configuration.`
 }
@@ -0,0 +1,49 @@
+package check
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestErrCheck(t *testing.T) {
+	dir := "testdata/errcheckrepo"
+	filenames := []string{dir + "/main.go"}
+	cases := []struct {
+		exclude []string
+		want    []string
+	}{
+		{
+			want: []string{
+				"14: warning: error returned by (*os.File).Close is not checked (errcheck)",
+				"19: warning: error returned by (*os.File).WriteString is not checked (errcheck)",
+				"24: warning: error returned by example.com/errcheckrepo.write is not checked (errcheck)",
+				"27: warning: error returned by remove is not checked (errcheck)",
+			},
+		},
+		{
+			exclude: append(append([]string{}, errcheckExclude...), "(*os.File).Close", "(*os.File).WriteString"),
+			want: []string{
+				"24: warning: error returned by example.com/errcheckrepo.write is not checked (errcheck)",
+				"27: warning: error returned by remove is not checked (errcheck)",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		_, summaries, err := ErrCheck{Dir: dir, Filenames: filenames, Driver: NewDriver(dir), Exclude: tt.exclude}.Percentage()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, fs := range summaries {
+			for _, e := range fs.Errors {
+				got = append(got, fmt.Sprintf("%d: %s", e.LineNumber, e.ErrorString))
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Exclude %v: errors = %q, want %q", tt.exclude, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity is how serious a finding is
+type Severity string
+
+// The severities a finding can have, from most to least serious
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank orders severities, with more serious ones ranking higher
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+
+	return 0
+}
+
+// ParseSeverity returns the severity with the given name
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(s))
+	if sev.rank() == 0 {
+		return "", fmt.Errorf("unknown severity %q", s)
+	}
+
+	return sev, nil
+}
+
+// Category is the kind of problem a finding points out
+type Category string
+
+// The categories findings are grouped in
+const (
+	CategoryBug         Category = "bug"
+	CategoryStyle       Category = "style"
+	CategoryFormat      Category = "format"
+	CategoryComplexity  Category = "complexity"
+	CategorySpelling    Category = "spelling"
+	CategoryTesting     Category = "testing"
+	CategoryLegal       Category = "legal"
+	CategorySecurity    Category = "security"
+	CategoryDuplication Category = "duplication"
+	CategoryDeprecation Category = "deprecation"
+)
+
+// Rule describes a kind of finding reported by a check
+type Rule struct {
+	// ID identifies the rule, and is stable across runs
+	ID       string   `json:"id"`
+	Severity Severity `json:"severity"`
+	Category Category `json:"category"`
+	// DocURL links to the documentation of the rule
+	DocURL string `json:"doc_url"`
+}
+
+// rules lists the rules that do not follow the defaults of lookupRule
+var rules = map[string]Rule{
+	"gofmt": {
+		Severity: SeverityInfo,
+		Category: CategoryFormat,
+		DocURL:   "https://pkg.go.dev/cmd/gofmt",
+	},
+	"syntax": {
+		Severity: SeverityError,
+		Category: CategoryBug,
+		DocURL:   "https://go.dev/ref/spec",
+	},
+	"gocyclo": {
+		Severity: SeverityWarning,
+		Category: CategoryComplexity,
+		DocURL:   "https://github.com/fzipp/gocyclo",
+	},
+	"cognitive": {
+		Severity: SeverityWarning,
+		Category: CategoryComplexity,
+		DocURL:   "https://www.sonarsource.com/docs/CognitiveComplexity.pdf",
+	},
+	"long-function": {
+		Severity: SeverityWarning,
+		Category: CategoryComplexity,
+	},
+	"long-file": {
+		Severity: SeverityInfo,
+		Category: CategoryComplexity,
+	},
+	"duplicate": {
+		Severity: SeverityWarning,
+		Category: CategoryDuplication,
+	},
+	"misspell": {
+		Severity: SeverityInfo,
+		Category: CategorySpelling,
+		DocURL:   "https://github.com/client9/misspell",
+	},
+	"ineffassign": {
+		Severity: SeverityWarning,
+		Category: CategoryBug,
+		DocURL:   "https://github.com/gordonklaus/ineffassign",
+	},
+	"golint": {
+		Severity: SeverityInfo,
+		Category: CategoryStyle,
+		DocURL:   "https://github.com/golang/lint",
+	},
+	"license": {
+		Severity: SeverityWarning,
+		Category: CategoryLegal,
+		DocURL:   "https://spdx.org/licenses/",
+	},
+	"missing-spdx-header": {
+		Severity: SeverityWarning,
+		Category: CategoryLegal,
+		DocURL:   "https://reuse.software/spec/",
+	},
+	"missing-copyright": {
+		Severity: SeverityWarning,
+		Category: CategoryLegal,
+		DocURL:   "https://reuse.software/spec/",
+	},
+	"license-mismatch": {
+		Severity: SeverityError,
+		Category: CategoryLegal,
+		DocURL:   "https://spdx.org/licenses/",
+	},
+	"missing-doc": {
+		Severity: SeverityInfo,
+		Category: CategoryStyle,
+		DocURL:   "https://go.dev/doc/comment",
+	},
+	"missing-package-doc": {
+		Severity: SeverityInfo,
+		Category: CategoryStyle,
+		DocURL:   "https://go.dev/doc/comment#package",
+	},
+	"untested-package": {
+		Severity: SeverityWarning,
+		Category: CategoryTesting,
+		DocURL:   "https://pkg.go.dev/testing",
+	},
+	"untested-func": {
+		Severity: SeverityInfo,
+		Category: CategoryTesting,
+		DocURL:   "https://pkg.go.dev/testing",
+	},
+	"missing-example": {
+		Severity: SeverityInfo,
+		Category: CategoryTesting,
+		DocURL:   "https://go.dev/blog/examples",
+	},
+	"vulnerable-symbol": {
+		Severity: SeverityError,
+		Category: CategorySecurity,
+		DocURL:   "https://go.dev/doc/security/vuln/",
+	},
+	"vulnerable-package": {
+		Severity: SeverityInfo,
+		Category: CategorySecurity,
+		DocURL:   "https://go.dev/doc/security/vuln/",
+	},
+	"vulnerable-module": {
+		Severity: SeverityInfo,
+		Category: CategorySecurity,
+		DocURL:   "https://go.dev/doc/security/vuln/",
+	},
+	"deprecated": {
+		Severity: SeverityWarning,
+		Category: CategoryDeprecation,
+	},
+	"errcheck": {
+		Severity: SeverityWarning,
+		Category: CategoryBug,
+		DocURL:   "https://github.com/kisielk/errcheck",
+	},
+}
+
+// lookupRule returns the rule with the given ID. Rules that are not
+// listed in rules, such as the go vet analyzers, are warnings about bugs.
+func lookupRule(id string) Rule {
+	r, ok := rules[id]
+	if !ok {
+		r = Rule{Severity: SeverityWarning, Category: CategoryBug}
+	}
+	r.ID = id
+
+	return r
+}
+
+// ruleError returns an Error for a finding of the rule with the given ID
+func ruleError(id string, line, column int, msg string) Error {
+	r := lookupRule(id)
+	return Error{
+		LineNumber:  line,
+		ErrorString: msg,
+		Column:      column,
+		Rule:        r.ID,
+		Severity:    r.Severity,
+		Category:    r.Category,
+		DocURL:      r.DocURL,
+	}
+}
+
+// Filter selects findings by their severity, category and rule.
+// The zero Filter matches every finding.
+type Filter struct {
+	// MinSeverity leaves out findings that are less serious
+	MinSeverity Severity
+	// Categories, if set, leaves out findings in other categories
+	Categories []Category
+	// Rules, if set, leaves out findings of other rules
+	Rules []string
+}
+
+// Match reports whether the finding is selected by the filter
+func (f Filter) Match(e Error) bool {
+	if f.MinSeverity != "" && e.Severity.rank() < f.MinSeverity.rank() {
+		return false
+	}
+	if len(f.Categories) > 0 && !contains(f.Categories, e.Category) {
+		return false
+	}
+	if len(f.Rules) > 0 && !contains(f.Rules, e.Rule) {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns the file summaries with only the findings selected by
+// the filter, leaving out files that have none left
+func (f Filter) Apply(summaries []FileSummary) []FileSummary {
+	var filtered = []FileSummary{}
+	for _, fs := range summaries {
+		var errs []Error
+		for _, e := range fs.Errors {
+			if f.Match(e) {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 0 {
+			continue
+		}
+		fs.Errors = errs
+		filtered = append(filtered, fs)
+	}
+
+	return filtered
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Finding is a single finding along with the file it was found in
+type Finding struct {
+	Filename string `json:"filename"`
+	FileURL  string `json:"file_url"`
+	Error
+}
+
+// Group is a set of findings that share a key,
+// such as their rule or their category
+type Group struct {
+	Key      string    `json:"key"`
+	Findings []Finding `json:"findings"`
+}
+
+// GroupBy groups the findings in summaries by "file", "rule",
+// "category" or "severity". Groups are sorted by key, and findings
+// keep the order they have in summaries.
+func GroupBy(summaries []FileSummary, by string) ([]Group, error) {
+	var key func(Finding) string
+	switch by {
+	case "file":
+		key = func(f Finding) string { return f.Filename }
+	case "rule":
+		key = func(f Finding) string { return f.Rule }
+	case "category":
+		key = func(f Finding) string { return string(f.Category) }
+	case "severity":
+		key = func(f Finding) string { return string(f.Severity) }
+	default:
+		return nil, fmt.Errorf("cannot group findings by %q", by)
+	}
+
+	var groups []Group
+	index := make(map[string]int)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			f := Finding{Filename: fs.Filename, FileURL: fs.FileURL, Error: e}
+			k := key(f)
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, Group{Key: k})
+			}
+			groups[i].Findings = append(groups[i].Findings, f)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+
+	return groups, nil
+}
@@ -0,0 +1,116 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddError(t *testing.T) {
+	cases := []struct {
+		out  string
+		want Error
+	}{
+		{
+			"a.go:12:5:warning: exported func Foo should have comment or be unexported (golint)",
+			Error{
+				LineNumber:  12,
+				ErrorString: "warning: exported func Foo should have comment or be unexported (golint)",
+				Column:      5,
+				Rule:        "golint",
+				Severity:    SeverityInfo,
+				Category:    CategoryStyle,
+				DocURL:      "https://github.com/golang/lint",
+			},
+		},
+		{
+			"a.go:7::warning: error return value not checked (errch)",
+			Error{
+				LineNumber:  7,
+				ErrorString: "warning: error return value not checked (errch)",
+				Rule:        "errch",
+				Severity:    SeverityWarning,
+				Category:    CategoryBug,
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		var fs FileSummary
+		if err := fs.AddError(tt.out); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fs.Errors, []Error{tt.want}) {
+			t.Errorf("AddError(%q) = %#v, want %#v", tt.out, fs.Errors, tt.want)
+		}
+	}
+}
+
+var findingSummaries = []FileSummary{
+	{
+		Filename: "a.go",
+		Errors: []Error{
+			ruleError("gofmt", 1, 0, "not formatted"),
+			ruleError("printf", 3, 2, "bad format"),
+		},
+	},
+	{
+		Filename: "b.go",
+		Errors: []Error{
+			ruleError("misspell", 4, 8, "misspelled"),
+		},
+	},
+}
+
+func TestFilter(t *testing.T) {
+	cases := []struct {
+		filter Filter
+		want   []string
+	}{
+		{Filter{}, []string{"gofmt", "printf", "misspell"}},
+		{Filter{MinSeverity: SeverityWarning}, []string{"printf"}},
+		{Filter{Categories: []Category{CategorySpelling, CategoryFormat}}, []string{"gofmt", "misspell"}},
+		{Filter{Rules: []string{"misspell"}}, []string{"misspell"}},
+		{Filter{MinSeverity: SeverityError}, nil},
+	}
+
+	for _, tt := range cases {
+		var got []string
+		for _, fs := range tt.filter.Apply(findingSummaries) {
+			for _, e := range fs.Errors {
+				got = append(got, e.Rule)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v.Apply() rules = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	cases := []struct {
+		by   string
+		want map[string]int
+	}{
+		{"file", map[string]int{"a.go": 2, "b.go": 1}},
+		{"category", map[string]int{"bug": 1, "format": 1, "spelling": 1}},
+		{"severity", map[string]int{"info": 2, "warning": 1}},
+	}
+
+	for _, tt := range cases {
+		groups, err := GroupBy(findingSummaries, tt.by)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]int)
+		for _, g := range groups {
+			got[g.Key] = len(g.Findings)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GroupBy(%q) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+
+	if _, err := GroupBy(findingSummaries, "color"); err == nil {
+		t.Errorf("GroupBy(%q) returned no error", "color")
+	}
+}
@@ -0,0 +1,66 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/atomicalign"
+	"golang.org/x/tools/go/analysis/passes/deepequalerrors"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/reflectvaluecompare"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/sortslice"
+	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+)
+
+// extendedVetAnalyzers are analyzers that go vet does not run, but
+// that find real bugs with few false positives. httpresponse is one
+// of them, but go vet runs it already.
+var extendedVetAnalyzers = []*analysis.Analyzer{
+	atomicalign.Analyzer,
+	deepequalerrors.Analyzer,
+	nilness.Analyzer,
+	reflectvaluecompare.Analyzer,
+	shadow.Analyzer,
+	sortslice.Analyzer,
+	unusedwrite.Analyzer,
+}
+
+// GoVetExtended is the check for the analyzers that go vet does not run
+type GoVetExtended struct {
+	Dir       string
+	Filenames []string
+	Driver    *Driver
+}
+
+// Name returns the name of the display name of the command
+func (g GoVetExtended) Name() string {
+	return "go_vet_extended"
+}
+
+// Weight returns the weight this check has in the overall average
+func (g GoVetExtended) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the percentage of .go files that pass the analyzers
+func (g GoVetExtended) Percentage() (float64, []FileSummary, error) {
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (g GoVetExtended) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return Analyze(ctx, g.Driver, g.Filenames, extendedVetAnalyzers...)
+}
+
+// Description returns the description of GoVetExtended
+func (g GoVetExtended) Description() string {
+	var names []string
+	for _, a := range extendedVetAnalyzers {
+		names = append(names, fmt.Sprintf(`<a href="%s">%s</a>`, a.URL, a.Name))
+	}
+
+	return fmt.Sprintf(`Runs analyzers from golang.org/x/tools that <code>go vet</code> does not, such as nilness, which finds nil dereferences and impossible comparisons, and shadow, which finds variables that shadow others. The analyzers are %s, and every finding names the analyzer that reported it.`, strings.Join(names, ", "))
+}
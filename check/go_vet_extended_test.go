@@ -0,0 +1,35 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoVetExtended(t *testing.T) {
+	dir := "testdata/vetrepo"
+	_, fs, err := GoVetExtended{Dir: dir, Filenames: []string{dir + "/main.go"}, Driver: NewDriver(dir)}.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type finding struct {
+		line int
+		rule string
+		msg  string
+	}
+	want := []finding{
+		{16, "nilness", "warning: nil dereference in field selection (nilness)"},
+		{24, "shadow", `warning: declaration of "err" shadows declaration at line 22 (shadow)`},
+		{31, "unusedwrite", "warning: unused write to field x (unusedwrite)"},
+		{35, "sortslice", "warning: sort.Slice's argument must be a slice; is called with *[]int (sortslice)"},
+	}
+	var got []finding
+	for _, f := range fs {
+		for _, e := range f.Errors {
+			got = append(got, finding{e.LineNumber, e.Rule, e.ErrorString})
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %+v, want %+v", got, want)
+	}
+}
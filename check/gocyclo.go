@@ -1,9 +1,38 @@
 package check
 
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/fzipp/gocyclo"
+)
+
+// cycloOver is the default cyclomatic complexity above
+// which functions are reported
+const cycloOver = 15
+
 // GoCyclo is the check for the go cyclo command
 type GoCyclo struct {
 	Dir       string
 	Filenames []string
+	// Over is the complexity above which functions are
+	// reported, cycloOver if it is not set
+	Over int
+
+	metricScoring
+}
+
+func (g GoCyclo) over() int {
+	if g.Over == 0 {
+		return cycloOver
+	}
+
+	return g.Over
 }
 
 // Name returns the name of the display name of the command
@@ -16,19 +45,88 @@ func (g GoCyclo) Weight() float64 {
 	return .10
 }
 
-// Percentage returns the percentage of .go files that pass gofmt
+// Percentage returns the average score of the .go files, where a file
+// without complex functions scores 1 and every function over the
+// threshold scales its score down by how far over the threshold it is
 func (g GoCyclo) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=gocyclo", "--cyclo-over=15"})
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (g GoCyclo) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	over := g.over()
+	var failed = []FileSummary{}
+	err := eachFile(ctx, g.Filenames, parser.ParseComments, func(fn string, _ []byte, fset *token.FileSet, f *ast.File) error {
+		filename := strings.TrimPrefix(fn, "_repos/src")
+		fs := FileSummary{
+			Filename: displayFilename(filename),
+			FileURL:  fileURL(filename),
+		}
+		for _, s := range cycloStats(fset, f, over) {
+			if s.Over == 0 {
+				continue
+			}
+			e := ruleError("gocyclo", s.StartLine, 0, fmt.Sprintf("warning: cyclomatic complexity %d of function %s() is high (> %d) (gocyclo)", s.Value, s.Name, over))
+			e.EndLine = s.EndLine
+			e.Function = &s
+			fs.Errors = append(fs.Errors, e)
+		}
+		if len(fs.Errors) > 0 {
+			failed = append(failed, fs)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	return metricScore(g.Filenames, failed), failed, nil
+}
+
+// cycloStats returns the cyclomatic complexity of every function in
+// a file, measured against over, most complex first
+func cycloStats(fset *token.FileSet, f *ast.File, over int) []FunctionMetric {
+	// gocyclo only reports where functions start,
+	// so look up where they end by their offset
+	ends := make(map[int]int)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			ends[fset.Position(n.Pos()).Offset] = fset.Position(n.End()).Line
+		}
+		return true
+	})
+
+	var metrics []FunctionMetric
+	for _, s := range gocyclo.AnalyzeASTFile(f, fset, nil) {
+		m := FunctionMetric{
+			Name:      s.FuncName,
+			Metric:    "cyclomatic complexity",
+			Value:     s.Complexity,
+			Threshold: over,
+			StartLine: s.Pos.Line,
+			EndLine:   ends[s.Pos.Offset],
+		}
+		if m.Value > m.Threshold {
+			m.Over = m.Value - m.Threshold
+		}
+		metrics = append(metrics, m)
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Value > metrics[j].Value
+	})
+
+	return metrics
 }
 
 // Description returns the description of GoCyclo
 func (g GoCyclo) Description() string {
-	return `<a href="https://github.com/fzipp/gocyclo">Gocyclo</a> calculates cyclomatic complexities of functions in Go source code.
+	return fmt.Sprintf(`<a href="https://github.com/fzipp/gocyclo">Gocyclo</a> calculates cyclomatic complexities of functions in Go source code.
 
 The cyclomatic complexity of a function is calculated according to the following rules:
 
 1 is the base complexity of a function
 +1 for each 'if', 'for', 'case', '&&' or '||'
 
-Go Report Card warns on functions with cyclomatic complexity > 15.`
+Go Report Card warns on functions with cyclomatic complexity > %[1]d. Each of them lowers the score of its file by the ratio of %[1]d to its complexity, so reducing complexity always improves the score.`, g.over())
 }
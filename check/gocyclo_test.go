@@ -0,0 +1,39 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoCyclo(t *testing.T) {
+	g := GoCyclo{Dir: "testdata/cyclo", Filenames: []string{"testdata/cyclo/a.go"}}
+	p, fs, err := g.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 15.0 / 17.0; p != want {
+		t.Errorf("GoCyclo percent = %f, want %f", p, want)
+	}
+
+	want := []FileSummary{
+		{
+			Filename: "testdata/cyclo/a.go",
+			Errors: []Error{
+				{
+					LineNumber:  10,
+					ErrorString: "warning: cyclomatic complexity 17 of function complex() is high (> 15) (gocyclo)",
+					EndLine:     41,
+					Rule:        "gocyclo",
+					Severity:    SeverityWarning,
+					Category:    CategoryComplexity,
+					DocURL:      "https://github.com/fzipp/gocyclo",
+					Function:    &FunctionMetric{Name: "complex", Metric: "cyclomatic complexity", Value: 17, Threshold: 15, Over: 2, StartLine: 10, EndLine: 41},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fs, want) {
+		t.Errorf("GoCyclo failed = %#v, want %#v", fs, want)
+	}
+}
@@ -1,5 +1,18 @@
 package check
 
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"strings"
+)
+
 // GoFmt is the check for the go fmt command
 type GoFmt struct {
 	Dir       string
@@ -18,11 +31,92 @@ func (g GoFmt) Weight() float64 {
 
 // Percentage returns the percentage of .go files that pass gofmt
 func (g GoFmt) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=gofmt"})
-	// return GoFmtNative(g.Dir, g.Filenames)
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext returns the percentage of .go files that pass gofmt
+func (g GoFmt) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return GoFmtNative(ctx, g.Dir, g.Filenames)
 }
 
 // Description returns the description of gofmt
 func (g GoFmt) Description() string {
 	return `Gofmt formats Go programs. We run <code>gofmt -s</code> on your code, where <code>-s</code> is for the <a href="https://golang.org/cmd/gofmt/#hdr-The_simplify_command">"simplify" command</a>`
 }
+
+// GoFmtNative runs gofmt -s on the given files in-process. Every file
+// that would be changed is reported with the line ranges that differ
+// and a unified diff of the changes.
+func GoFmtNative(ctx context.Context, dir string, filenames []string) (float64, []FileSummary, error) {
+	var failed = []FileSummary{}
+	for _, fn := range filenames {
+		if err := ctx.Err(); err != nil {
+			return 0, []FileSummary{}, err
+		}
+
+		src, err := os.ReadFile(fn)
+		if err != nil {
+			return 0, []FileSummary{}, err
+		}
+
+		filename := strings.TrimPrefix(fn, "_repos/src")
+		fs := FileSummary{
+			Filename: displayFilename(filename),
+			FileURL:  fileURL(filename),
+		}
+
+		res, err := gofmtSimplify(fn, src)
+		if err != nil {
+			// gofmt cannot format files that do not parse
+			var list scanner.ErrorList
+			if !errors.As(err, &list) || len(list) == 0 {
+				return 0, []FileSummary{}, err
+			}
+			pos := list[0].Pos
+			fs.Errors = append(fs.Errors, ruleError("syntax", pos.Line, pos.Column, fmt.Sprintf("warning: file could not be parsed: %s (gofmt)", list[0].Msg)))
+			failed = append(failed, fs)
+			continue
+		}
+
+		diff, changed := unifiedDiff(fs.Filename+".orig", src, fs.Filename, res)
+		if len(changed) == 0 {
+			continue
+		}
+
+		fs.Diff = string(diff)
+		for _, r := range changed {
+			e := ruleError("gofmt", r.Start, 0, "warning: file is not gofmted with -s (gofmt)")
+			if r.End > r.Start {
+				e.ErrorString = fmt.Sprintf("warning: file is not gofmted with -s, lines %d-%d differ (gofmt)", r.Start, r.End)
+				e.EndLine = r.End
+			}
+			fs.Errors = append(fs.Errors, e)
+		}
+		failed = append(failed, fs)
+	}
+
+	p, err := passRatio(filenames, failed)
+	if err != nil {
+		return 0, failed, err
+	}
+
+	return p, failed, nil
+}
+
+// gofmtSimplify returns src formatted the same way as gofmt -s would
+func gofmtSimplify(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	simplify(f)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+package check
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var gofmtSimplifyTests = []struct {
+	name string
+	src  string
+	want string
+}{
+	{
+		"composite literal",
+		"package p\n\nvar x = []T{T{1}, T{2}}\n",
+		"package p\n\nvar x = []T{{1}, {2}}\n",
+	},
+	{
+		"slice expression",
+		"package p\n\nvar y = s[1:len(s)]\n",
+		"package p\n\nvar y = s[1:]\n",
+	},
+	{
+		"range",
+		"package p\n\nfunc f() {\n\tfor _ = range s {\n\t}\n}\n",
+		"package p\n\nfunc f() {\n\tfor range s {\n\t}\n}\n",
+	},
+	{
+		"formatted",
+		"package p\n\nvar z = 1\n",
+		"package p\n\nvar z = 1\n",
+	},
+}
+
+func TestGoFmtSimplify(t *testing.T) {
+	for _, tt := range gofmtSimplifyTests {
+		got, err := gofmtSimplify("a.go", []byte(tt.src))
+		if err != nil {
+			t.Fatalf("[%s] gofmtSimplify: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("[%s] gofmtSimplify = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGoFmtNative(t *testing.T) {
+	p, fs, err := GoFmtNative(context.Background(), "testdata/testrepo@v0.1.0", []string{"testdata/testrepo@v0.1.0/a.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != 0.875 {
+		t.Errorf("GoFmtNative percent = %f, want %f", p, 0.875)
+	}
+	if len(fs) != 1 {
+		t.Fatalf("GoFmtNative returned %d file summaries, want 1", len(fs))
+	}
+
+	want := []Error{{
+		LineNumber:  5,
+		ErrorString: "warning: file is not gofmted with -s (gofmt)",
+		Rule:        "gofmt",
+		Severity:    SeverityInfo,
+		Category:    CategoryFormat,
+		DocURL:      "https://pkg.go.dev/cmd/gofmt",
+	}}
+	if !reflect.DeepEqual(fs[0].Errors, want) {
+		t.Errorf("GoFmtNative errors = %v, want %v", fs[0].Errors, want)
+	}
+	if !strings.Contains(fs[0].Diff, "-    func main() {\n+func main() {\n") {
+		t.Errorf("GoFmtNative diff = %q, want it to contain the change to func main", fs[0].Diff)
+	}
+}
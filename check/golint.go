@@ -1,9 +1,23 @@
 package check
 
-// GoLint is the check for the go lint command
+import (
+	"context"
+	"go/token"
+
+	"golang.org/x/lint"
+	"golang.org/x/tools/go/analysis"
+)
+
+// golintMinConfidence is the confidence below
+// which golint problems are not reported
+const golintMinConfidence = 0.85
+
+// GoLint is the check for golint, which runs in-process
+// on the packages loaded by the driver
 type GoLint struct {
 	Dir       string
 	Filenames []string
+	Driver    *Driver
 }
 
 // Name returns the name of the display name of the command
@@ -18,10 +32,57 @@ func (g GoLint) Weight() float64 {
 
 // Percentage returns the percentage of .go files that pass golint
 func (g GoLint) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=golint", "--min-confidence=0.85", "--vendor"})
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (g GoLint) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return Analyze(ctx, g.Driver, g.Filenames, golintAnalyzer)
+}
+
+// golintAnalyzer lints the files of a package with golint,
+// which lints a package at a time
+var golintAnalyzer = &analysis.Analyzer{
+	Name: "golint",
+	Doc:  "report style mistakes that golint finds",
+	URL:  "https://github.com/golang/lint",
+	Run:  runGolint,
+}
+
+func runGolint(pass *analysis.Pass) (interface{}, error) {
+	files := make(map[string]*token.File)
+	srcs := make(map[string][]byte)
+	for _, f := range pass.Files {
+		tf := pass.Fset.File(f.Pos())
+		src, err := pass.ReadFile(tf.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[tf.Name()] = tf
+		srcs[tf.Name()] = src
+	}
+
+	problems, err := new(lint.Linter).LintFiles(srcs)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range problems {
+		tf, ok := files[p.Position.Filename]
+		if !ok || p.Confidence < golintMinConfidence {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      tf.LineStart(p.Position.Line) + token.Pos(p.Position.Column-1),
+			Message:  p.Text,
+			Category: p.Category,
+			URL:      p.Link,
+		})
+	}
+
+	return nil, nil
 }
 
 // Description returns the description of go lint
 func (g GoLint) Description() string {
-	return `Golint is a linter for Go source code.`
+	return `<a href="https://github.com/golang/lint">Golint</a> is a linter for Go source code, which reports style mistakes from <a href="https://go.dev/wiki/CodeReviewComments">Go Code Review Comments</a>.`
 }
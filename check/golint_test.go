@@ -0,0 +1,32 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoLint(t *testing.T) {
+	dir := "testdata/lintrepo"
+	_, fs, err := GoLint{Dir: dir, Filenames: []string{dir + "/lint.go"}, Driver: NewDriver(dir)}.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type finding struct {
+		line int
+		msg  string
+	}
+	want := []finding{
+		{4, "warning: exported function Exported should have comment or be unexported (golint)"},
+		{8, `warning: receiver name should be a reflection of its identity; don't use generic names such as "this" or "self" (golint)`},
+	}
+	var got []finding
+	for _, f := range fs {
+		for _, e := range f.Errors {
+			got = append(got, finding{e.LineNumber, e.ErrorString})
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %+v, want %+v", got, want)
+	}
+}
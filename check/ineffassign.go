@@ -1,5 +1,11 @@
 package check
 
+import (
+	"context"
+
+	"github.com/gordonklaus/ineffassign/pkg/ineffassign"
+)
+
 // IneffAssign is the check for the ineffassign command
 type IneffAssign struct {
 	Dir       string
@@ -16,9 +22,14 @@ func (g IneffAssign) Weight() float64 {
 	return 0.10
 }
 
-// Percentage returns the percentage of .go files that pass gofmt
+// Percentage returns the percentage of .go files that pass ineffassign
 func (g IneffAssign) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=ineffassign"})
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext returns the percentage of .go files that pass ineffassign
+func (g IneffAssign) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return AnalyzeFiles(ctx, g.Filenames, ineffassign.Analyzer)
 }
 
 // Description returns the description of IneffAssign
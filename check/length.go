@@ -0,0 +1,173 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// The default lengths above which functions and files are reported
+const (
+	statementsOver = 40
+	linesOver      = 60
+	fileLinesOver  = 1000
+)
+
+// Length is the check for long functions and files
+type Length struct {
+	Dir       string
+	Filenames []string
+	// Statements and Lines are the lengths above which functions are
+	// reported, and FileLines the length above which files are. Each
+	// has its default if it is not set.
+	Statements int
+	Lines      int
+	FileLines  int
+
+	metricScoring
+}
+
+func (l Length) thresholds() (statements, lines, fileLines int) {
+	statements, lines, fileLines = l.Statements, l.Lines, l.FileLines
+	if statements == 0 {
+		statements = statementsOver
+	}
+	if lines == 0 {
+		lines = linesOver
+	}
+	if fileLines == 0 {
+		fileLines = fileLinesOver
+	}
+
+	return statements, lines, fileLines
+}
+
+// Name returns the name of the display name of the command
+func (l Length) Name() string {
+	return "length"
+}
+
+// Weight returns the weight this check has in the overall average
+func (l Length) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the average score of the .go files, where a file
+// without long functions scores 1, and every function or file over a
+// threshold scales its score down by how far over the threshold it is
+func (l Length) Percentage() (float64, []FileSummary, error) {
+	return l.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (l Length) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	statements, lines, fileLines := l.thresholds()
+	var failed = []FileSummary{}
+	err := eachFile(ctx, l.Filenames, 0, func(fn string, src []byte, fset *token.FileSet, f *ast.File) error {
+		file, funcs := lengthStats(fset, f, src)
+		filename := strings.TrimPrefix(fn, "_repos/src")
+		fs := FileSummary{
+			Filename: displayFilename(filename),
+			FileURL:  fileURL(filename),
+		}
+		if m := file.over(fileLines); m != nil {
+			e := ruleError("long-file", 1, 0, fmt.Sprintf("warning: file has %d lines (> %d) (length)", m.Value, m.Threshold))
+			e.Function = m
+			fs.Errors = append(fs.Errors, e)
+		}
+		for _, f := range funcs {
+			for _, m := range []*FunctionMetric{f.statements.over(statements), f.lines.over(lines)} {
+				if m == nil {
+					continue
+				}
+				e := ruleError("long-function", m.StartLine, 0, fmt.Sprintf("warning: function %s() has %d %s (> %d) (length)", m.Name, m.Value, m.Metric, m.Threshold))
+				e.EndLine = m.EndLine
+				e.Function = m
+				fs.Errors = append(fs.Errors, e)
+			}
+		}
+		if len(fs.Errors) > 0 {
+			failed = append(failed, fs)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	return metricScore(l.Filenames, failed), failed, nil
+}
+
+// length is a measured length, before it is held against a threshold
+type length FunctionMetric
+
+// over returns the length as a metric if it is over threshold
+func (m length) over(threshold int) *FunctionMetric {
+	if m.Value <= threshold {
+		return nil
+	}
+
+	fm := FunctionMetric(m)
+	fm.Threshold = threshold
+	fm.Over = m.Value - threshold
+	return &fm
+}
+
+// funcLength is the length of a function in statements and in lines
+type funcLength struct {
+	statements, lines length
+}
+
+// lengthStats returns the length of a file in lines,
+// and the lengths of the functions declared in it
+func lengthStats(fset *token.FileSet, f *ast.File, src []byte) (length, []funcLength) {
+	n := bytes.Count(src, []byte{'\n'})
+	file := length{Metric: "lines", Value: n, StartLine: 1, EndLine: n}
+
+	var funcs []funcLength
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+
+		m := length{
+			Name:      strings.TrimSuffix(funcName(fd), "()"),
+			StartLine: fset.Position(fd.Pos()).Line,
+			EndLine:   fset.Position(fd.End()).Line,
+		}
+		statements, lines := m, m
+		statements.Metric, statements.Value = "statements", countStatements(fd.Body)
+		lines.Metric, lines.Value = "lines", m.EndLine-m.StartLine+1
+		funcs = append(funcs, funcLength{statements, lines})
+	}
+
+	return file, funcs
+}
+
+// countStatements counts the statements in a function body, including
+// the ones of the function literals in it. Blocks and the clauses of
+// switch and select statements only group statements, and labels only
+// name the statement they label, so they do not count.
+func countStatements(body *ast.BlockStmt) int {
+	var n int
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.EmptyStmt, *ast.LabeledStmt:
+		case ast.Stmt:
+			n++
+		}
+		return true
+	})
+
+	return n
+}
+
+// Description returns the description of Length
+func (l Length) Description() string {
+	statements, lines, fileLines := l.thresholds()
+	return fmt.Sprintf(`Reports functions with more than %d statements or %d lines, and files with more than %d lines. Long functions are hard to follow even when their code is straight-line, which cyclomatic complexity does not count. Each of them lowers the score of its file by the ratio of the threshold to its length.`, statements, lines, fileLines)
+}
@@ -0,0 +1,123 @@
+package check
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	cases := []struct {
+		fileLines  int
+		percentage float64
+		metrics    []FunctionMetric
+	}{
+		{
+			fileLines:  0,
+			percentage: 40.0 / 46.0 * 60.0 / 66.0,
+			metrics: []FunctionMetric{
+				{Name: "straight", Metric: "statements", Value: 46, Threshold: 40, Over: 6, StartLine: 4, EndLine: 51},
+				{Name: "tall", Metric: "lines", Value: 66, Threshold: 60, Over: 6, StartLine: 54, EndLine: 119},
+			},
+		},
+		{
+			fileLines:  100,
+			percentage: 100.0 / 121.0 * 40.0 / 46.0 * 60.0 / 66.0,
+			metrics: []FunctionMetric{
+				{Metric: "lines", Value: 121, Threshold: 100, Over: 21, StartLine: 1, EndLine: 121},
+				{Name: "straight", Metric: "statements", Value: 46, Threshold: 40, Over: 6, StartLine: 4, EndLine: 51},
+				{Name: "tall", Metric: "lines", Value: 66, Threshold: 60, Over: 6, StartLine: 54, EndLine: 119},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		l := Length{Dir: "testdata/length", Filenames: []string{"testdata/length/a.go"}, FileLines: tt.fileLines}
+		p, fs, err := l.Percentage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != tt.percentage {
+			t.Errorf("FileLines %d: Percentage() = %f, want %f", tt.fileLines, p, tt.percentage)
+		}
+		if len(fs) != 1 {
+			t.Fatalf("FileLines %d: got %d file summaries, want 1", tt.fileLines, len(fs))
+		}
+
+		var metrics []FunctionMetric
+		for _, e := range fs[0].Errors {
+			metrics = append(metrics, *e.Function)
+		}
+		if !reflect.DeepEqual(metrics, tt.metrics) {
+			t.Errorf("FileLines %d: metrics = %+v, want %+v", tt.fileLines, metrics, tt.metrics)
+		}
+	}
+}
+
+func TestCountStatements(t *testing.T) {
+	cases := []struct {
+		body string
+		want int
+	}{
+		{"", 0},
+		{"a := 1; a++", 2},
+		{"if true { return }", 2},
+		{"switch { case true: f(); default: g() }", 3},
+		{"f := func() { g() }; f()", 3},
+		{"outer: for { for { break outer } }", 3},
+		{"goto end; end: f()", 2},
+	}
+
+	for _, tt := range cases {
+		src := "package p\n\nfunc f() {" + tt.body + "}\n"
+		f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := f.Decls[0].(*ast.FuncDecl).Body
+		if got := countStatements(body); got != tt.want {
+			t.Errorf("countStatements(%q) = %d, want %d", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestLengthMessages(t *testing.T) {
+	l := Length{Dir: "testdata/length", Filenames: []string{"testdata/length/a.go"}, FileLines: 100}
+	_, fs, err := l.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"warning: file has 121 lines (> 100) (length)",
+		"warning: function straight() has 46 statements (> 40) (length)",
+		"warning: function tall() has 66 lines (> 60) (length)",
+	}
+	for i, e := range fs[0].Errors {
+		if i < len(want) && e.ErrorString != want[i] {
+			t.Errorf("error %d = %q, want %q", i, e.ErrorString, want[i])
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	cfg := Config{Checks: map[string]CheckConfig{
+		"length": {Thresholds: map[string]int{"lines": 100}},
+	}}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"statements": statementsOver, "lines": 100, "file_lines": fileLinesOver}
+	if got := cfg.Thresholds("length"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Thresholds(%q) = %v, want %v", "length", got, want)
+	}
+
+	for _, thresholds := range []map[string]int{{"width": 10}, {"lines": 0}} {
+		cfg := Config{Checks: map[string]CheckConfig{"length": {Thresholds: thresholds}}}
+		if err := cfg.validate(); err == nil {
+			t.Errorf("validate() with thresholds %v = nil, want an error", thresholds)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package check
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/google/licensecheck"
 )
 
-// License is the check for the existence of a license file
+// License is the check for a license file with a known license
 type License struct {
-	Dir       string
+	Dir string
+	// Root, if set, is the root of the repository Dir is in,
+	// whose license covers Dir too
+	Root      string
 	Filenames []string
 }
 
@@ -32,31 +40,186 @@ var licenses = []string{
 	"copyleft",
 }
 
-// Percentage returns 0 if no LICENSE, 1 if LICENSE
+// minLicenseConfidence is the share of a license file that has to match
+// known license texts for the license to get full marks
+const minLicenseConfidence = .8
+
+// The scores of license files whose license is not fully recognized
+const (
+	partialLicenseScore = .75
+	unknownLicenseScore = .5
+)
+
+// LicenseInfo describes the license detected in a license file
+type LicenseInfo struct {
+	// File is the name of the license file
+	File string `json:"file"`
+	// SPDXID is the SPDX identifier of the license, joined by " AND " if
+	// the file has more than one license. It is empty if the text of the
+	// file does not match any known license.
+	SPDXID string `json:"spdx_id"`
+	// Confidence is the share of the words in the file
+	// that match known license texts, from 0 to 1
+	Confidence  float64 `json:"confidence"`
+	OSIApproved bool    `json:"osi_approved"`
+	// Explanation says why the license does not get full marks
+	Explanation string `json:"explanation,omitempty"`
+
+	score float64
+}
+
+// Percentage returns 1 for a license file that matches a known license,
+// partial credit for a license file that does not, and 0 without one
 func (g License) Percentage() (float64, []FileSummary, error) {
-	files, err := os.ReadDir(g.Dir)
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but returns ctx.Err() if ctx is
+// done before the license is detected
+func (g License) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	info, ok, err := g.Detect()
 	if err != nil {
 		return 0.0, []FileSummary{}, err
 	}
+	if !ok {
+		return 0.0, []FileSummary{{Filename: "", FileURL: "http://choosealicense.com/", Errors: []Error{}}}, nil
+	}
+	if info.Explanation == "" {
+		return info.score, []FileSummary{}, nil
+	}
 
-	for _, file := range files {
-		name := strings.ToLower(file.Name())
+	filename := strings.TrimPrefix(info.File, "_repos/src")
+	return info.score, []FileSummary{{
+		Filename: displayFilename(filename),
+		FileURL:  fileURL(filename),
+		Errors:   []Error{ruleError("license", 1, 0, fmt.Sprintf("warning: %s (license)", info.Explanation))},
+	}}, nil
+}
+
+// Detect detects the license of the check's directory, or of the
+// root of the repository if the directory has no license file
+func (g License) Detect() (LicenseInfo, bool, error) {
+	dirs := []string{g.Dir}
+	if g.Root != "" && filepath.Clean(g.Root) != filepath.Clean(g.Dir) {
+		dirs = append(dirs, g.Root)
+	}
+
+	for _, dir := range dirs {
+		info, ok, err := detectLicense(dir)
+		if err != nil || ok {
+			return info, ok, err
+		}
+	}
+
+	return LicenseInfo{}, false, nil
+}
+
+// licenseInfo returns the license of dir, or of root if dir has no
+// license file, for the report. It is nil if there is none.
+func licenseInfo(dir, root string) *LicenseInfo {
+	info, ok, err := License{Dir: dir, Root: root}.Detect()
+	if err != nil || !ok {
+		return nil
+	}
+	info.File = displayFilename(strings.TrimPrefix(info.File, "_repos/src"))
+
+	return &info
+}
 
-		if filepath.Ext(name) == "go" {
+// detectLicense matches the license files in dir against known license
+// texts, and returns the license of the one that matches best
+func detectLicense(dir string) (LicenseInfo, bool, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return LicenseInfo{}, false, err
+	}
+
+	var best LicenseInfo
+	var found bool
+	for _, file := range files {
+		if file.IsDir() || !isLicenseFile(file.Name()) {
 			continue
 		}
 
-		for i := range licenses {
-			if strings.HasPrefix(name, licenses[i]) {
-				return 1.0, []FileSummary{}, nil
-			}
+		fn := filepath.Join(dir, file.Name())
+		text, err := os.ReadFile(fn)
+		if err != nil {
+			return LicenseInfo{}, false, err
+		}
+
+		info := classifyLicense(fn, text)
+		if !found || info.score > best.score || (info.score == best.score && info.Confidence > best.Confidence) {
+			best, found = info, true
+		}
+	}
+
+	return best, found, nil
+}
+
+// isLicenseFile reports whether a file is named like a license file
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	if filepath.Ext(name) == ".go" {
+		return false
+	}
+
+	for i := range licenses {
+		if strings.HasPrefix(name, licenses[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifyLicense matches the text of a license file against known license texts
+func classifyLicense(filename string, text []byte) LicenseInfo {
+	info := LicenseInfo{File: filename}
+	if len(bytes.TrimSpace(text)) == 0 {
+		info.Explanation = fmt.Sprintf("%s is empty", filepath.Base(filename))
+		return info
+	}
+
+	cov := licensecheck.Scan(text)
+	var ids []string
+	for _, m := range cov.Match {
+		if !contains(ids, m.ID) {
+			ids = append(ids, m.ID)
 		}
 	}
+	info.Confidence = cov.Percent / 100
+	if len(ids) == 0 {
+		info.score = unknownLicenseScore
+		info.Explanation = fmt.Sprintf("%s does not match any known license, so users cannot tell what they may do with the code", filepath.Base(filename))
+		return info
+	}
+
+	info.SPDXID = strings.Join(ids, " AND ")
+	info.OSIApproved = true
+	for _, id := range ids {
+		info.OSIApproved = info.OSIApproved && isOSIApproved(id)
+	}
+	info.score = 1
+	if info.Confidence < minLicenseConfidence {
+		info.score = partialLicenseScore
+		info.Explanation = fmt.Sprintf("only %.0f%% of %s matches the text of %s, and the rest may change its terms", info.Confidence*100, filepath.Base(filename), info.SPDXID)
+	}
+
+	return info
+}
 
-	return 0.0, []FileSummary{{"", "http://choosealicense.com/", []Error{}}}, nil
+// score scores the check by how well the license was recognized,
+// which is the same whatever findings are left
+func (g License) score(filenames []string, failed []FileSummary) (float64, error) {
+	info, _, err := g.Detect()
+	return info.score, err
 }
 
 // Description returns the description of License
 func (g License) Description() string {
-	return "Checks whether your project has a LICENSE file."
+	return "Checks whether your project has a LICENSE file, and whether its text matches a known <a href=\"https://spdx.org/licenses/\">SPDX</a> license."
 }
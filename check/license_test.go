@@ -3,12 +3,48 @@ package check
 import "testing"
 
 func TestPercentage(t *testing.T) {
-	g := License{"testdata/testfiles", []string{}}
-	p, _, err := g.Percentage()
+	cases := []struct {
+		dir         string
+		percentage  float64
+		spdxID      string
+		osiApproved bool
+		explained   bool
+	}{
+		{"testdata/licenses/mit", 1, "MIT", true, false},
+		{"testdata/licenses/partial", partialLicenseScore, "MIT", true, true},
+		{"testdata/licenses/unknown", unknownLicenseScore, "", false, true},
+		// LICENCE.txt is empty
+		{"testdata/testfiles", 0, "", false, true},
+		{"testdata/licenses/none", 0, "", false, false},
+	}
+
+	for _, tt := range cases {
+		g := License{Dir: tt.dir, Filenames: []string{}}
+		p, _, err := g.Percentage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != tt.percentage {
+			t.Errorf("%s: Percentage() = %f, want %f", tt.dir, p, tt.percentage)
+		}
+
+		info, _, err := g.Detect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.SPDXID != tt.spdxID || info.OSIApproved != tt.osiApproved || (info.Explanation != "") != tt.explained {
+			t.Errorf("%s: Detect() = %+v, want SPDX ID %q, OSI approved %t, explained %t", tt.dir, info, tt.spdxID, tt.osiApproved, tt.explained)
+		}
+	}
+}
+
+func TestDetectRoot(t *testing.T) {
+	g := License{Dir: "testdata/licenses/none", Root: "testdata/licenses/mit"}
+	info, ok, err := g.Detect()
 	if err != nil {
 		t.Fatal(err)
 	}
-	if p != 1.0 {
-		t.Errorf("License check failed")
+	if !ok || info.SPDXID != "MIT" {
+		t.Errorf("Detect() = %+v, %t, want the MIT license of the root", info, ok)
 	}
 }
@@ -0,0 +1,223 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	spdxRx      = regexp.MustCompile(`SPDX-License-Identifier:\s*(.*?)\s*(\*/)?\s*$`)
+	copyrightRx = regexp.MustCompile(`(?i)SPDX-FileCopyrightText:|copyright\s+(\(c\)|©|\d{4})`)
+)
+
+// LicenseHeaders is the check for SPDX license and copyright headers
+// in every file, as the REUSE specification asks for
+type LicenseHeaders struct {
+	Dir string
+	// Root, if set, is the root of the repository Dir is in,
+	// whose license covers Dir if it has none of its own
+	Root      string
+	Filenames []string
+}
+
+// Name returns the name of the display name of the command
+func (g LicenseHeaders) Name() string {
+	return "license_headers"
+}
+
+// Weight returns the weight this check has in the overall average
+func (g LicenseHeaders) Weight() float64 {
+	return .05
+}
+
+// Percentage returns the percentage of .go files with an SPDX
+// license header and a copyright header that agree with the
+// license of the directory they are in
+func (g LicenseHeaders) Percentage() (float64, []FileSummary, error) {
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (g LicenseHeaders) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	root, _, err := License{Dir: g.Dir, Root: g.Root}.Detect()
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	// the licenses of the directories below Dir that have their own
+	dirs := make(map[string]LicenseInfo)
+	dirLicense := func(fn string) (LicenseInfo, error) {
+		top := filepath.Clean(g.Dir)
+		for dir := filepath.Dir(fn); dir != top && dir != "." && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			info, ok := dirs[dir]
+			if !ok {
+				var found bool
+				info, found, err = detectLicense(dir)
+				if err != nil {
+					return LicenseInfo{}, err
+				}
+				if !found {
+					info = LicenseInfo{}
+				}
+				dirs[dir] = info
+			}
+			if info.File != "" {
+				return info, nil
+			}
+		}
+
+		return root, nil
+	}
+
+	var failed = []FileSummary{}
+	add := func(fn string, errs []Error) {
+		if len(errs) == 0 {
+			return
+		}
+		filename := strings.TrimPrefix(fn, "_repos/src")
+		failed = append(failed, FileSummary{
+			Filename: displayFilename(filename),
+			FileURL:  fileURL(filename),
+			Errors:   errs,
+		})
+	}
+
+	for _, fn := range g.Filenames {
+		if err := ctx.Err(); err != nil {
+			return 0, []FileSummary{}, err
+		}
+
+		license, err := dirLicense(fn)
+		if err != nil {
+			return 0, []FileSummary{}, err
+		}
+		h := readLicenseHeader(fn)
+
+		var errs []Error
+		switch {
+		case h.spdx == "":
+			errs = append(errs, ruleError("missing-spdx-header", 1, 0, "warning: file has no SPDX-License-Identifier header (license_headers)"))
+		case license.SPDXID != "" && !sameLicense(h.spdx, license.SPDXID):
+			errs = append(errs, ruleError("license-mismatch", h.spdxLine, 0, fmt.Sprintf("warning: SPDX-License-Identifier %s disagrees with %s in %s (license_headers)", h.spdx, license.SPDXID, displayLicenseFile(license.File))))
+		}
+		if !h.copyright {
+			errs = append(errs, ruleError("missing-copyright", 1, 0, "warning: file has no copyright header (license_headers)"))
+		}
+		add(fn, errs)
+	}
+
+	var dirnames []string
+	for dir, info := range dirs {
+		if info.SPDXID != "" && root.SPDXID != "" && !sameLicense(info.SPDXID, root.SPDXID) {
+			dirnames = append(dirnames, dir)
+		}
+	}
+	sort.Strings(dirnames)
+	for _, dir := range dirnames {
+		info := dirs[dir]
+		add(info.File, []Error{ruleError("license-mismatch", 1, 0, fmt.Sprintf("warning: directory %s is licensed under %s, which differs from %s in %s (license_headers)", displayLicenseFile(dir), info.SPDXID, root.SPDXID, displayLicenseFile(root.File)))})
+	}
+
+	p, err := g.score(g.Filenames, failed)
+	return p, failed, err
+}
+
+// score returns the share of .go files without findings, leaving
+// out the findings about license files of directories
+func (g LicenseHeaders) score(filenames []string, failed []FileSummary) (float64, error) {
+	var files int
+	for _, fs := range failed {
+		if strings.HasSuffix(fs.Filename, ".go") {
+			files++
+		}
+	}
+
+	return ratio(len(filenames)-files, len(filenames)), nil
+}
+
+// licenseHeader is what the header of a file says about its license
+type licenseHeader struct {
+	// spdx is the SPDX license expression of the file,
+	// and spdxLine the line it is on
+	spdx      string
+	spdxLine  int
+	copyright bool
+}
+
+// readLicenseHeader reads the license and copyright headers in the
+// comments before the package clause of a file
+func readLicenseHeader(filename string) licenseHeader {
+	var h licenseHeader
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, filename, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if f == nil {
+		return h
+	}
+
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			for i, line := range strings.Split(c.Text, "\n") {
+				if m := spdxRx.FindStringSubmatch(line); m != nil && h.spdx == "" {
+					h.spdx = m[1]
+					h.spdxLine = fset.Position(c.Pos()).Line + i
+				}
+				if copyrightRx.MatchString(line) {
+					h.copyright = true
+				}
+			}
+		}
+	}
+
+	return h
+}
+
+// sameLicense reports whether two SPDX license expressions
+// have a license in common
+func sameLicense(a, b string) bool {
+	ids := licenseIDs(b)
+	for _, id := range licenseIDs(a) {
+		if contains(ids, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// licenseIDs returns the license identifiers in an SPDX license
+// expression, without the "-only" and "-or-later" suffixes of
+// the GNU licenses
+func licenseIDs(expr string) []string {
+	var ids []string
+	for _, f := range strings.FieldsFunc(expr, func(r rune) bool { return r == ' ' || r == '(' || r == ')' }) {
+		switch strings.ToUpper(f) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		f = strings.TrimSuffix(f, "+")
+		f = strings.TrimSuffix(f, "-only")
+		f = strings.TrimSuffix(f, "-or-later")
+		ids = append(ids, f)
+	}
+
+	return ids
+}
+
+// displayLicenseFile returns the name a license file is shown with
+func displayLicenseFile(filename string) string {
+	return displayFilename(strings.TrimPrefix(filename, "_repos/src"))
+}
+
+// Description returns the description of LicenseHeaders
+func (g LicenseHeaders) Description() string {
+	return `Checks that every file has an <code>SPDX-License-Identifier</code> and a copyright header, as the <a href="https://reuse.software/spec/">REUSE specification</a> asks for, and that they agree with the license of the directory the file is in.`
+}
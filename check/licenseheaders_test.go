@@ -0,0 +1,57 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLicenseHeaders(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/reuse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, summaries, err := LicenseHeaders{Dir: "testdata/reuse", Filenames: filenames}.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ok.go, dual.go and other/other.go pass
+	if want := 3.0 / 5.0; p != want {
+		t.Errorf("Percentage() = %f, want %f", p, want)
+	}
+
+	got := make(map[string][]string)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			got[fs.Filename] = append(got[fs.Filename], e.Rule)
+		}
+	}
+	want := map[string][]string{
+		"testdata/reuse/missing.go":    {"missing-spdx-header", "missing-copyright"},
+		"testdata/reuse/mismatch.go":   {"license-mismatch"},
+		"testdata/reuse/other/LICENSE": {"license-mismatch"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules of findings = %v, want %v", got, want)
+	}
+}
+
+func TestSameLicense(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"MIT", "MIT", true},
+		{"Apache-2.0 OR MIT", "MIT", true},
+		{"(MIT AND BSD-3-Clause)", "BSD-3-Clause", true},
+		{"GPL-2.0-or-later", "GPL-2.0", true},
+		{"GPL-3.0-only", "MIT", false},
+	}
+
+	for _, tt := range cases {
+		if got := sameLicense(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameLicense(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,47 @@
 package check
 
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"sync"
+
+	"github.com/client9/misspell"
+	"golang.org/x/tools/go/analysis"
+)
+
+// misspellReplacer compiles the misspell dictionary on first use
+var misspellReplacer = sync.OnceValue(misspell.New)
+
+// misspellAnalyzer reports commonly misspelled English words
+// in comments
+var misspellAnalyzer = &analysis.Analyzer{
+	Name: "misspell",
+	Doc:  "find commonly misspelled English words in comments",
+	Run:  runMisspell,
+}
+
+func runMisspell(pass *analysis.Pass) (interface{}, error) {
+	r := misspellReplacer()
+	for _, f := range pass.Files {
+		tf := pass.Fset.File(f.Pos())
+		src, err := pass.ReadFile(tf.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		_, diffs := r.ReplaceGo(string(src))
+		for _, d := range diffs {
+			pass.Report(analysis.Diagnostic{
+				Pos:     tf.LineStart(d.Line) + token.Pos(d.Column),
+				Message: fmt.Sprintf("%q is a misspelling of %q", d.Original, d.Corrected),
+			})
+		}
+	}
+
+	return nil, nil
+}
+
 // Misspell is the check for the misspell command
 type Misspell struct {
 	Dir       string
@@ -16,9 +58,14 @@ func (g Misspell) Weight() float64 {
 	return 0.0
 }
 
-// Percentage returns the percentage of .go files that pass gofmt
+// Percentage returns the percentage of .go files that pass misspell
 func (g Misspell) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=misspell"})
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext returns the percentage of .go files that pass misspell
+func (g Misspell) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	return AnalyzeFiles(ctx, g.Filenames, misspellAnalyzer)
 }
 
 // Description returns the description of Misspell
@@ -0,0 +1,260 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// errNoGoFiles is returned by runModule for modules without Go files
+var errNoGoFiles = errors.New("no .go files found")
+
+// module is a Go module in a directory being checked
+type module struct {
+	// Dir is the directory of the module,
+	// relative to the directory being checked
+	Dir string
+	// Path is the module path declared in the go.mod file
+	Path string
+}
+
+// ModuleResult is the result of checking one module of
+// a directory with nested modules
+type ModuleResult struct {
+	// Dir is the directory of the module,
+	// relative to the directory that was checked
+	Dir string `json:"dir"`
+	// Path is the module path declared in the go.mod file,
+	// which the directory that was checked might not have
+	Path string `json:"path"`
+	ChecksResult
+}
+
+// findModules returns the modules in dir, starting with dir itself
+// whether it has a go.mod file or not. Directories that are always
+// skipped or match one of the exclude patterns are not searched.
+func findModules(dir string, exclude []string) ([]module, error) {
+	var modules []module
+	err := filepath.Walk(dir, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil // continue walking elsewhere
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, fp)
+		if err != nil {
+			return err
+		}
+		if rel != "." && (contains(skipDirs, fi.Name()) || strings.HasPrefix(fi.Name(), ".") || excluded(exclude, rel)) {
+			return filepath.SkipDir
+		}
+
+		b, err := os.ReadFile(filepath.Join(fp, "go.mod"))
+		switch {
+		case err == nil:
+			modules = append(modules, module{Dir: filepath.ToSlash(rel), Path: modfile.ModulePath(b)})
+		case rel == ".":
+			modules = append(modules, module{Dir: "."})
+		}
+
+		return nil
+	})
+
+	return modules, err
+}
+
+// isModuleRoot reports whether the directory dir has a go.mod file
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// moduleExclude returns the exclude patterns of the directory being
+// checked as they apply inside the nested module at dir. Patterns
+// without a slash match anywhere and are kept, the others lose their
+// leading elements that match dir, or are dropped if they cannot
+// match anything inside it.
+func moduleExclude(patterns []string, dir string) []string {
+	elems := strings.Split(dir, "/")
+	var rebased []string
+outer:
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			rebased = append(rebased, pattern)
+			continue
+		}
+		parts := strings.Split(pattern, "/")
+		if len(parts) <= len(elems) {
+			continue
+		}
+		for i, elem := range elems {
+			if ok, _ := filepath.Match(parts[i], elem); !ok {
+				continue outer
+			}
+		}
+		rebased = append(rebased, strings.Join(parts[len(elems):], "/"))
+	}
+
+	return rebased
+}
+
+// runModules runs the checks on every module in dir and aggregates
+// their results, weighting the modules by their number of files and
+// the weight each of them gives every check
+func runModules(ctx context.Context, dir string, cfg Config, modules []module, opts Options) (ChecksResult, error) {
+	var resp ChecksResult
+	var results []ModuleResult
+	for _, m := range modules {
+		mdir := filepath.Join(dir, m.Dir)
+		mcfg := cfg
+		if m.Dir != "." {
+			mcfg.Exclude = moduleExclude(cfg.Exclude, m.Dir)
+			if _, err := os.Stat(filepath.Join(mdir, ConfigFilename)); err == nil {
+				if mcfg, err = LoadConfig(mdir); err != nil {
+					return ChecksResult{}, fmt.Errorf("module %s: %v", m.Dir, err)
+				}
+			}
+		}
+
+		r, err := runModule(ctx, dir, mdir, mcfg, opts)
+		if errors.Is(err, errNoGoFiles) {
+			resp.SkippedFiles = append(resp.SkippedFiles, SkippedFile{m.Dir, "module without .go files"})
+			continue
+		}
+		if err != nil {
+			return ChecksResult{}, fmt.Errorf("module %s: %v", m.Dir, err)
+		}
+		results = append(results, ModuleResult{Dir: m.Dir, Path: m.Path, ChecksResult: r})
+	}
+	if len(results) == 0 {
+		return ChecksResult{}, errNoGoFiles
+	}
+
+	for _, r := range results {
+		resp.Files += r.Files
+		resp.SkippedFiles = append(resp.SkippedFiles, r.SkippedFiles...)
+		for _, t := range r.Targets {
+			if !contains(resp.Targets, t) {
+				resp.Targets = append(resp.Targets, t)
+			}
+		}
+	}
+	// the configuration and license of dir apply to the whole
+	// directory, even if dir itself has no .go files
+	resp.Config = cfg.effective(nil)
+	for _, r := range results {
+		if r.Dir == "." {
+			resp.Config = r.Config
+		}
+	}
+	resp.License = licenseInfo(dir, dir)
+	resp.Modules = results
+	resp.tally(aggregate(results))
+
+	return resp, nil
+}
+
+// aggregate combines the scores every module has for each check.
+// Percentages and weights are averaged over the modules by their
+// number of files, with the percentage of every module counting as
+// much as the weight the module gives the check. Modules in which the
+// check timed out or was skipped are left out.
+func aggregate(results []ModuleResult) []Score {
+	type moduleScore struct {
+		Score
+		dir   string
+		files int
+	}
+
+	var names []string
+	byName := make(map[string][]moduleScore)
+	for _, r := range results {
+		for _, s := range r.Checks {
+			if _, ok := byName[s.Name]; !ok {
+				names = append(names, s.Name)
+			}
+			byName[s.Name] = append(byName[s.Name], moduleScore{s, r.Dir, r.Files})
+		}
+	}
+	sort.Strings(names)
+
+	var scores []Score
+	for _, name := range names {
+		ms := byName[name]
+		agg := Score{
+			Name:          name,
+			Description:   ms[0].Description,
+			FileSummaries: []FileSummary{},
+		}
+
+		var total, weight, allWeight float64
+		var files, allFiles, timedOut, skipped int
+		var errs []string
+		for _, m := range ms {
+			agg.FileSummaries = append(agg.FileSummaries, m.FileSummaries...)
+			agg.Suppressed = append(agg.Suppressed, m.Suppressed...)
+			agg.Breakdown = addFamilyCounts(agg.Breakdown, m.Breakdown)
+			allWeight += m.Weight * float64(m.files)
+			allFiles += m.files
+			switch {
+			case m.TimedOut:
+				timedOut++
+				continue
+			case m.Skipped != "":
+				skipped++
+				continue
+			case m.Error != "":
+				errs = append(errs, fmt.Sprintf("%s: %s", m.dir, m.Error))
+			}
+			total += m.Percentage * m.Weight * float64(m.files)
+			weight += m.Weight * float64(m.files)
+			files += m.files
+		}
+		switch {
+		case files > 0:
+			agg.Weight = weight / float64(files)
+			if weight > 0 {
+				agg.Percentage = total / weight
+			}
+		case allFiles > 0:
+			agg.Weight = allWeight / float64(allFiles)
+		}
+		agg.Error = strings.Join(errs, "; ")
+
+		switch {
+		case timedOut == len(ms):
+			agg.TimedOut = true
+			agg.Error = "timed out in every module"
+		case timedOut+skipped == len(ms):
+			agg.Skipped = ms[len(ms)-1].Skipped
+		}
+		scores = append(scores, agg)
+	}
+
+	return scores
+}
+
+// addFamilyCounts adds the counts of findings of every family in
+// counts to the ones in sum, and appends the families sum lacks
+func addFamilyCounts(sum, counts []FamilyCount) []FamilyCount {
+	for _, c := range counts {
+		i := 0
+		for i < len(sum) && sum[i].Family != c.Family {
+			i++
+		}
+		if i == len(sum) {
+			sum = append(sum, FamilyCount{Family: c.Family, Title: c.Title})
+		}
+		sum[i].Count += c.Count
+	}
+
+	return sum
+}
@@ -0,0 +1,164 @@
+package check
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindModules(t *testing.T) {
+	cases := []struct {
+		dir     string
+		exclude []string
+		want    []module
+	}{
+		{"testdata/multimod", nil, []module{
+			{Dir: ".", Path: "example.com/multimod"},
+			{Dir: "empty", Path: "example.com/multimod/empty"},
+			{Dir: "sub", Path: "example.com/multimod/sub"},
+			{Dir: "tools", Path: "example.com/multimod/tools"},
+		}},
+		{"testdata/multimod", []string{"sub", "tools"}, []module{
+			{Dir: ".", Path: "example.com/multimod"},
+			{Dir: "empty", Path: "example.com/multimod/empty"},
+		}},
+		{"testdata/cyclo", nil, []module{{Dir: "."}}},
+	}
+
+	for _, tt := range cases {
+		got, err := findModules(tt.dir, tt.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("findModules(%q, %q) = %+v, want %+v", tt.dir, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestRunModules(t *testing.T) {
+	cr, err := Run("testdata/multimod", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cr.Modules) != 3 {
+		t.Fatalf("got %d modules, want 3", len(cr.Modules))
+	}
+
+	want := []struct {
+		dir   string
+		files int
+	}{
+		{".", 2},
+		{"sub", 1},
+		{"tools", 1},
+	}
+	var files int
+	for i, m := range cr.Modules {
+		if m.Dir != want[i].dir || m.Files != want[i].files {
+			t.Errorf("module %d = %s with %d files, want %s with %d files", i, m.Dir, m.Files, want[i].dir, want[i].files)
+		}
+		files += m.Files
+	}
+	if cr.Files != files {
+		t.Errorf("cr.Files = %d, want %d", cr.Files, files)
+	}
+	// the missing license counts once, along with the misspelling in sub
+	if cr.Issues != 2 {
+		t.Errorf("cr.Issues = %d, want %d", cr.Issues, 2)
+	}
+	if cr.Modules[1].Config.Scoring != ScoringFiles {
+		t.Errorf("sub module scoring = %q, want %q", cr.Modules[1].Config.Scoring, ScoringFiles)
+	}
+	if cr.Config.Scoring != ScoringDensity {
+		t.Errorf("cr.Config.Scoring = %q, want the root module's %q", cr.Config.Scoring, ScoringDensity)
+	}
+
+	var skipped, excluded bool
+	for _, sf := range cr.SkippedFiles {
+		switch {
+		case sf.Filename == "empty":
+			skipped = true
+		case filepath.ToSlash(sf.Filename) == "testdata/multimod/tools/gen/gen.go":
+			excluded = true
+		}
+	}
+	if !skipped {
+		t.Errorf("cr.SkippedFiles = %+v, want the empty module", cr.SkippedFiles)
+	}
+	// the root exclude pattern tools/gen/*.go applies
+	// inside tools, which has no configuration of its own
+	if !excluded {
+		t.Errorf("cr.SkippedFiles = %+v, want tools/gen excluded by the root configuration", cr.SkippedFiles)
+	}
+}
+
+func TestModuleExclude(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		dir      string
+		want     []string
+	}{
+		{[]string{"*_mock.go", "sub/gen/*.go", "lib/*.go"}, "sub", []string{"*_mock.go", "gen/*.go"}},
+		{[]string{"*/gen/*.go", "sub"}, "sub", []string{"gen/*.go", "sub"}},
+		{[]string{"a/b/c/*.go", "a/*"}, "a/b", []string{"c/*.go"}},
+		{nil, "sub", nil},
+	}
+
+	for _, tt := range cases {
+		if got := moduleExclude(tt.patterns, tt.dir); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("moduleExclude(%q, %q) = %q, want %q", tt.patterns, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	results := []ModuleResult{
+		{Dir: ".", ChecksResult: ChecksResult{Files: 2, Checks: []Score{
+			{Name: "gocyclo", Weight: 0.25, Percentage: 1},
+			{Name: "golint", Weight: 0.1, TimedOut: true},
+		}}},
+		{Dir: "sub", ChecksResult: ChecksResult{Files: 1, Checks: []Score{
+			{Name: "gocyclo", Weight: 0.5, Percentage: 0},
+			{Name: "golint", Weight: 0.3, Percentage: 0.5},
+		}}},
+	}
+
+	want := []struct {
+		name       string
+		weight     float64
+		percentage float64
+	}{
+		// both modules weigh 0.5, two files by 0.25 and one by 0.5
+		{"gocyclo", 1.0 / 3, 0.5},
+		// golint timed out in the root module
+		{"golint", 0.3, 0.5},
+	}
+	scores := aggregate(results)
+	if len(scores) != len(want) {
+		t.Fatalf("got %d scores, want %d", len(scores), len(want))
+	}
+	for i, w := range want {
+		s := scores[i]
+		if s.Name != w.name || s.Weight != w.weight || s.Percentage != w.percentage {
+			t.Errorf("score %d = %s with weight %f and percentage %f, want %s with weight %f and percentage %f", i, s.Name, s.Weight, s.Percentage, w.name, w.weight, w.percentage)
+		}
+	}
+}
+
+func TestAggregateBreakdown(t *testing.T) {
+	results := []ModuleResult{
+		{Dir: ".", ChecksResult: ChecksResult{Files: 1, Checks: []Score{
+			{Name: "staticcheck", Weight: 0.15, Breakdown: []FamilyCount{{"SA", "bugs", 2}, {"ST", "style", 1}}},
+		}}},
+		{Dir: "sub", ChecksResult: ChecksResult{Files: 1, Checks: []Score{
+			{Name: "staticcheck", Weight: 0.15, Breakdown: []FamilyCount{{"SA", "bugs", 1}, {"U", "unused code", 3}}},
+		}}},
+	}
+
+	want := []FamilyCount{{"SA", "bugs", 3}, {"ST", "style", 1}, {"U", "unused code", 3}}
+	if got := aggregate(results)[0].Breakdown; !reflect.DeepEqual(got, want) {
+		t.Errorf("breakdown = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package check
+
+import "fmt"
+
+// Scoring is how findings are turned into the percentage of a check
+type Scoring string
+
+const (
+	// ScoringFiles scores a check by the share of files without findings,
+	// the way Go Report Card has always scored checks
+	ScoringFiles Scoring = "files"
+	// ScoringDensity scores a check by its findings per thousand lines
+	// of code, weighted by their severity
+	ScoringDensity Scoring = "density"
+)
+
+// DefaultScoring is the scoring used unless it is configured otherwise
+const DefaultScoring = ScoringDensity
+
+// halfDensity is the number of weighted findings per thousand lines
+// at which a check scores 50% with ScoringDensity
+const halfDensity = 10.0
+
+// severityWeights is how much a finding of each severity counts
+// towards the density of findings
+var severityWeights = map[Severity]float64{
+	SeverityError:   2,
+	SeverityWarning: 1,
+	SeverityInfo:    0.5,
+}
+
+// ParseScoring returns the scoring with the given name
+func ParseScoring(s string) (Scoring, error) {
+	switch sc := Scoring(s); sc {
+	case ScoringFiles, ScoringDensity:
+		return sc, nil
+	}
+
+	return "", fmt.Errorf("unknown scoring %q, want %q or %q", s, ScoringFiles, ScoringDensity)
+}
+
+// scorer is implemented by checks that score their findings
+// their own way, whatever the configured Scoring is
+type scorer interface {
+	score(filenames []string, failed []FileSummary) (float64, error)
+}
+
+// metricScoring is embedded in checks that report measurements over
+// a threshold, to score what is left of their findings by metricScore
+type metricScoring struct{}
+
+func (metricScoring) score(filenames []string, failed []FileSummary) (float64, error) {
+	return metricScore(filenames, failed), nil
+}
+
+// metricScore averages the scores of the files, where every finding
+// about a measurement over its threshold scales the score of its
+// file down by the ratio of the threshold to the measurement
+func metricScore(filenames []string, failed []FileSummary) float64 {
+	total := float64(len(filenames) - len(failed))
+	for _, fs := range failed {
+		score := 1.0
+		for _, e := range fs.Errors {
+			if e.Function != nil {
+				score *= float64(e.Function.Threshold) / float64(e.Function.Value)
+			}
+		}
+		total += score
+	}
+
+	return total / float64(len(filenames))
+}
+
+// scoring scores the findings of the checks in a run
+type scoring struct {
+	mode      Scoring
+	filenames []string
+	// lines is the number of lines in filenames,
+	// only counted for ScoringDensity
+	lines int
+}
+
+// newScoring returns the scoring of findings in filenames
+func newScoring(mode Scoring, filenames []string) (scoring, error) {
+	sc := scoring{mode: mode, filenames: filenames}
+	if mode != ScoringDensity {
+		return sc, nil
+	}
+
+	for _, fn := range filenames {
+		lc, err := lineCount(fn)
+		if err != nil {
+			return sc, err
+		}
+		sc.lines += lc
+	}
+
+	return sc, nil
+}
+
+// score scores the findings of a check
+func (sc scoring) score(c Check, failed []FileSummary) (float64, error) {
+	if w, ok := c.(weighted); ok {
+		c = w.Check
+	}
+	if s, ok := c.(scorer); ok {
+		return s.score(sc.filenames, failed)
+	}
+	if sc.mode == ScoringDensity {
+		return densityScore(sc.lines, failed), nil
+	}
+
+	return passRatio(sc.filenames, failed)
+}
+
+// densityScore scores findings by how many there are per thousand
+// lines, weighted by severity. No findings score 1, and the score
+// halves at halfDensity weighted findings per thousand lines.
+func densityScore(lines int, failed []FileSummary) float64 {
+	var weighted float64
+	for _, fs := range failed {
+		for _, e := range fs.Errors {
+			w, ok := severityWeights[e.Severity]
+			if !ok {
+				w = severityWeights[SeverityWarning]
+			}
+			weighted += w
+		}
+	}
+	if weighted == 0 {
+		return 1
+	}
+	if lines == 0 {
+		return 0
+	}
+
+	density := weighted / (float64(lines) / 1000)
+
+	return 1 / (1 + density/halfDensity)
+}
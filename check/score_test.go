@@ -0,0 +1,62 @@
+package check
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDensityScore(t *testing.T) {
+	errs := func(sevs ...Severity) []FileSummary {
+		fs := FileSummary{Filename: "a.go"}
+		for _, s := range sevs {
+			fs.Errors = append(fs.Errors, Error{Severity: s})
+		}
+		return []FileSummary{fs}
+	}
+
+	cases := []struct {
+		name   string
+		lines  int
+		failed []FileSummary
+		want   float64
+	}{
+		{"no findings", 1000, []FileSummary{}, 1},
+		{"no lines", 0, errs(SeverityWarning), 0},
+		{"half density", 1000, errs(SeverityWarning, SeverityWarning, SeverityWarning, SeverityWarning, SeverityError, SeverityError, SeverityInfo, SeverityInfo, ""), 0.5},
+		{"typo in a large file", 5000, errs(SeverityInfo), 1 / (1 + 0.1/halfDensity)},
+		{"vet errors in a small file", 10, errs(SeverityWarning, SeverityWarning), 1 / (1 + 200/halfDensity)},
+	}
+
+	for _, tt := range cases {
+		if got := densityScore(tt.lines, tt.failed); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("[%s] densityScore(%d) = %f, want %f", tt.name, tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestParseScoring(t *testing.T) {
+	for _, s := range []string{"files", "density"} {
+		if _, err := ParseScoring(s); err != nil {
+			t.Errorf("ParseScoring(%q) = %v, want no error", s, err)
+		}
+	}
+	if _, err := ParseScoring("kloc"); err == nil {
+		t.Errorf("ParseScoring(%q) returned no error", "kloc")
+	}
+}
+
+func TestMetricScoring(t *testing.T) {
+	failed := []FileSummary{{
+		Filename: "a.go",
+		Errors:   []Error{{Function: &FunctionMetric{Value: 20, Threshold: 10}}},
+	}}
+	sc := scoring{mode: ScoringDensity, filenames: []string{"a.go", "b.go"}, lines: 100}
+
+	// checks of measurements over a threshold score by how
+	// far over it they are, whatever the configured scoring
+	for _, c := range []Check{GoCyclo{}, Cognitive{}, Length{}} {
+		if got, err := sc.score(c, failed); err != nil || got != .75 {
+			t.Errorf("score(%s) = %f, %v, want %f", c.Name(), got, err, .75)
+		}
+	}
+}
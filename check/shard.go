@@ -0,0 +1,123 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// shardSize is the number of files that are parsed
+// and analyzed together by runSyntactic
+const shardSize = 50
+
+// shard splits filenames into shards of at most size files
+func shard(filenames []string, size int) [][]string {
+	var shards [][]string
+	for len(filenames) > size {
+		shards = append(shards, filenames[:size:size])
+		filenames = filenames[size:]
+	}
+	if len(filenames) > 0 {
+		shards = append(shards, filenames)
+	}
+
+	return shards
+}
+
+// runSyntactic runs analyzers that only look at the syntax of files,
+// not at their types, and so do not need their packages to be loaded.
+// The files are split into shards that a pool of workers analyzes in
+// parallel, so that repositories of any size are analyzed in bounded
+// memory. The diagnostics are sorted by position.
+func runSyntactic(ctx context.Context, filenames []string, analyzers ...*analysis.Analyzer) ([]Diagnostic, error) {
+	for _, a := range analyzers {
+		if len(a.Requires) > 0 {
+			return nil, fmt.Errorf("analyzer %s needs the results of other analyzers", a.Name)
+		}
+	}
+
+	shards := make(chan []string)
+	go func() {
+		defer close(shards)
+		for _, s := range shard(filenames, shardSize) {
+			select {
+			case shards <- s:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		diags    []Diagnostic
+		firstErr error
+	)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range shards {
+				d, err := analyzeShard(s, analyzers)
+				mu.Lock()
+				diags = append(diags, d...)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	sortDiagnostics(diags)
+
+	return diags, nil
+}
+
+// analyzeShard parses a shard of files and runs the analyzers on them.
+// Files that do not parse are left out, gofmt reports them.
+func analyzeShard(filenames []string, analyzers []*analysis.Analyzer) ([]Diagnostic, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, fn := range filenames {
+		f, err := parser.ParseFile(fset, fn, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	var diags []Diagnostic
+	for _, a := range analyzers {
+		pass := &analysis.Pass{
+			Analyzer: a,
+			Fset:     fset,
+			Files:    files,
+			ResultOf: map[*analysis.Analyzer]interface{}{},
+			ReadFile: os.ReadFile,
+			Report: func(ad analysis.Diagnostic) {
+				diags = append(diags, newDiagnostic(a, fset, ad))
+			},
+		}
+		if _, err := a.Run(pass); err != nil {
+			return nil, fmt.Errorf("%s: %v", a.Name, err)
+		}
+	}
+
+	return diags, nil
+}
@@ -0,0 +1,72 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gordonklaus/ineffassign/pkg/ineffassign"
+)
+
+func TestShard(t *testing.T) {
+	cases := []struct {
+		n, size int
+		want    []int
+	}{
+		{0, 50, nil},
+		{10, 50, []int{10}},
+		{50, 50, []int{50}},
+		{120, 50, []int{50, 50, 20}},
+	}
+
+	for _, tt := range cases {
+		filenames := make([]string, tt.n)
+		var got []int
+		for _, s := range shard(filenames, tt.size) {
+			got = append(got, len(s))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("shard(%d files, %d) sizes = %v, want %v", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeFilesLarge(t *testing.T) {
+	// more files than misspell and ineffassign used to be
+	// disabled for, spread over several shards
+	dir := t.TempDir()
+	var filenames []string
+	for i := 0; i < 3*shardSize+7; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("f%d.go", i))
+		src := fmt.Sprintf("package p\n\n// f%[1]d is obivous\nfunc f%[1]d() int {\n\tx := 1\n\tx = 2\n\treturn 0\n}\n", i)
+		if err := os.WriteFile(fn, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, fn)
+	}
+
+	p, fs, err := AnalyzeFiles(context.Background(), filenames, misspellAnalyzer, ineffassign.Analyzer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != 0 {
+		t.Errorf("AnalyzeFiles percent = %f, want 0", p)
+	}
+	if len(fs) != len(filenames) {
+		t.Fatalf("AnalyzeFiles reported findings in %d files, want %d", len(fs), len(filenames))
+	}
+	for _, f := range fs {
+		if len(f.Errors) != 3 {
+			t.Errorf("AnalyzeFiles reported %d findings in %s, want 3", len(f.Errors), f.Filename)
+		}
+	}
+}
+
+func TestGoToolNotInstalled(t *testing.T) {
+	_, _, err := GoTool("testdata/testfiles", []string{"testdata/testfiles/a.go"}, []string{"goreportcard-no-such-tool"})
+	if _, ok := err.(*SkipError); !ok {
+		t.Errorf("GoTool with a missing command returned %v, want a *SkipError", err)
+	}
+}
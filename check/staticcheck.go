@@ -1,9 +1,88 @@
 package check
 
-// Staticcheck is the check for the staticcheck command
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/quickfix"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+)
+
+// staticcheckFamily is a family of staticcheck rules,
+// whose codes all start with the family's name
+type staticcheckFamily struct {
+	name     string
+	title    string
+	severity Severity
+	category Category
+	// enabled is whether the family runs by default
+	enabled   bool
+	analyzers []*lint.Analyzer
+}
+
+// staticcheckFamilies lists the rule families of staticcheck. Unused
+// code is found by an analyzer whose results are merged across
+// packages, so the U family has no analyzers of its own.
+var staticcheckFamilies = []staticcheckFamily{
+	{"SA", "bugs", SeverityWarning, CategoryBug, true, staticcheck.Analyzers},
+	{"S", "simplifications", SeverityInfo, CategoryStyle, true, simple.Analyzers},
+	{"ST", "style", SeverityInfo, CategoryStyle, true, stylecheck.Analyzers},
+	{"QF", "quick fixes", SeverityInfo, CategoryStyle, false, quickfix.Analyzers},
+	{"U", "unused code", SeverityWarning, CategoryStyle, true, nil},
+}
+
+// staticcheckDefaults returns the rule families of staticcheck,
+// mapped to whether they run by default
+func staticcheckDefaults() map[string]bool {
+	families := make(map[string]bool)
+	for _, f := range staticcheckFamilies {
+		families[f.name] = f.enabled
+	}
+
+	return families
+}
+
+// ruleFamily returns the family of a staticcheck rule, such as "SA" for SA4006
+func ruleFamily(rule string) (staticcheckFamily, bool) {
+	name := strings.TrimRight(rule, "0123456789")
+	for _, f := range staticcheckFamilies {
+		if f.name == name && name != rule {
+			return f, true
+		}
+	}
+
+	return staticcheckFamily{}, false
+}
+
+// Staticcheck is the check for the staticcheck analyzers, which
+// run in-process on the packages loaded by the driver
 type Staticcheck struct {
 	Dir       string
 	Filenames []string
+	Driver    *Driver
+	// Families lists the rule families that run, such as "SA" and
+	// "ST", the ones that run by default if it is not set
+	Families []string
+}
+
+// families returns the rule families that run
+func (g Staticcheck) families() []staticcheckFamily {
+	var families []staticcheckFamily
+	for _, f := range staticcheckFamilies {
+		if g.Families == nil && f.enabled || contains(g.Families, f.name) {
+			families = append(families, f)
+		}
+	}
+
+	return families
 }
 
 // Name returns the name of the display name of the command
@@ -18,10 +97,150 @@ func (g Staticcheck) Weight() float64 {
 
 // Percentage returns the percentage of .go files that pass
 func (g Staticcheck) Percentage() (float64, []FileSummary, error) {
-	return GoTool(g.Dir, g.Filenames, []string{"gometalinter", "--deadline=180s", "--disable-all", "--enable=staticcheck"})
+	return g.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (g Staticcheck) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	var analyzers []*analysis.Analyzer
+	var u *unusedObjects
+	for _, f := range g.families() {
+		for _, a := range f.analyzers {
+			if !a.Doc.NonDefault {
+				analyzers = append(analyzers, a.Analyzer)
+			}
+		}
+		if f.name == "U" {
+			u = &unusedObjects{used: make(map[unusedKey]bool)}
+			analyzers = append(analyzers, u.analyzer())
+		}
+	}
+
+	diags, err := g.Driver.Run(ctx, analyzers...)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+	if u != nil {
+		diags = append(diags, u.diagnostics()...)
+		sortDiagnostics(diags)
+	}
+
+	p, failed, err := summarize(g.Filenames, diags)
+	for _, fs := range failed {
+		for i, e := range fs.Errors {
+			if f, ok := ruleFamily(e.Rule); ok {
+				fs.Errors[i].Severity, fs.Errors[i].Category = f.severity, f.category
+			}
+		}
+	}
+
+	return p, failed, err
+}
+
+// breakdown counts the findings of every family of rules that ran
+func (g Staticcheck) breakdown(summaries []FileSummary) []FamilyCount {
+	counts := make(map[string]int)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			if f, ok := ruleFamily(e.Rule); ok {
+				counts[f.name]++
+			}
+		}
+	}
+
+	var breakdown []FamilyCount
+	for _, f := range g.families() {
+		breakdown = append(breakdown, FamilyCount{Family: f.name, Title: f.title, Count: counts[f.name]})
+	}
+
+	return breakdown
+}
+
+// unusedKey identifies an object across the packages it is
+// analyzed in, such as a package and its test variant
+type unusedKey struct {
+	pkgPath string
+	base    string
+	line    int
+	name    string
+}
+
+// unusedObjects collects the results of the unused analyzer for every
+// package. An object is unused if it is unused in all of the packages
+// it is analyzed in, the way the staticcheck command merges them.
+type unusedObjects struct {
+	mu      sync.Mutex
+	used    map[unusedKey]bool
+	objects []unused.Object
+	keys    []unusedKey
+}
+
+// analyzer returns an analyzer that records the results of the unused
+// analyzer. It reports no diagnostics, see diagnostics instead.
+func (u *unusedObjects) analyzer() *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "unused",
+		Doc:      "record unused code",
+		Requires: []*analysis.Analyzer{unused.Analyzer.Analyzer},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			res := pass.ResultOf[unused.Analyzer.Analyzer].(unused.Result)
+			key := func(obj unused.Object) unusedKey {
+				return unusedKey{pass.Pkg.Path(), filepath.Base(obj.Position.Filename), obj.Position.Line, obj.Name}
+			}
+
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			for _, obj := range res.Used {
+				u.used[key(obj)] = true
+			}
+			for _, obj := range res.Unused {
+				k := key(obj)
+				u.objects = append(u.objects, obj)
+				u.keys = append(u.keys, k)
+				if _, ok := u.used[k]; !ok {
+					u.used[k] = false
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+// diagnostics returns a diagnostic for every object that is unused
+// in all of the packages it was analyzed in
+func (u *unusedObjects) diagnostics() []Diagnostic {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for i, obj := range u.objects {
+		if u.used[u.keys[i]] {
+			continue
+		}
+		diag := Diagnostic{
+			Analyzer: unused.Analyzer.Analyzer.Name,
+			Filename: obj.DisplayPosition.Filename,
+			Line:     obj.DisplayPosition.Line,
+			Column:   obj.DisplayPosition.Column,
+			Message:  fmt.Sprintf("%s %s is unused", obj.Kind, obj.Name),
+			URL:      "https://staticcheck.dev/docs/checks/#" + unused.Analyzer.Analyzer.Name,
+		}
+		if !seen[diag.key()] {
+			seen[diag.key()] = true
+			diags = append(diags, diag)
+		}
+	}
+
+	return diags
 }
 
 // Description returns the description of Staticcheck
 func (g Staticcheck) Description() string {
-	return `<a href="https://staticcheck.io">Staticcheck</a> finds bugs, performance issues, and more`
+	var names []string
+	for _, f := range g.families() {
+		names = append(names, fmt.Sprintf("%s (%s)", f.name, f.title))
+	}
+
+	return fmt.Sprintf(`<a href="https://staticcheck.dev">Staticcheck</a> finds bugs, performance issues, and more. Rules of the families %s are run.`, strings.Join(names, ", "))
 }
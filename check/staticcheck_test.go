@@ -0,0 +1,73 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticcheck(t *testing.T) {
+	dir := "testdata/testfiles"
+	filenames := []string{dir + "/a.go", dir + "/b.go", dir + "/c.go", dir + "/d.go"}
+	cases := []struct {
+		families  []string
+		percent   float64
+		errors    []Error
+		breakdown []FamilyCount
+	}{
+		{
+			percent: 0.75,
+			errors: []Error{
+				{LineNumber: 8, Column: 6, ErrorString: "warning: func foo is unused (U1000)", Rule: "U1000", Severity: SeverityWarning, Category: CategoryStyle, DocURL: "https://staticcheck.dev/docs/checks/#U1000"},
+				{LineNumber: 10, Column: 7, EndLine: 10, EndColumn: 24, ErrorString: "warning: should use time.Until instead of t.Sub(time.Now()) (S1024)", Rule: "S1024", Severity: SeverityInfo, Category: CategoryStyle, DocURL: "https://staticcheck.dev/docs/checks/#S1024"},
+			},
+			breakdown: []FamilyCount{
+				{Family: "SA", Title: "bugs"},
+				{Family: "S", Title: "simplifications", Count: 1},
+				{Family: "ST", Title: "style"},
+				{Family: "U", Title: "unused code", Count: 1},
+			},
+		},
+		{
+			families:  []string{"SA"},
+			percent:   1,
+			breakdown: []FamilyCount{{Family: "SA", Title: "bugs"}},
+		},
+	}
+
+	driver := NewDriver(dir)
+	for _, tt := range cases {
+		s := Staticcheck{Dir: dir, Filenames: filenames, Driver: driver, Families: tt.families}
+		p, fs, err := s.Percentage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != tt.percent {
+			t.Errorf("Families %v: Percentage() = %f, want %f", tt.families, p, tt.percent)
+		}
+
+		var errs []Error
+		for _, f := range fs {
+			errs = append(errs, f.Errors...)
+		}
+		if !reflect.DeepEqual(errs, tt.errors) {
+			t.Errorf("Families %v: errors = %#v, want %#v", tt.families, errs, tt.errors)
+		}
+		if got := s.breakdown(fs); !reflect.DeepEqual(got, tt.breakdown) {
+			t.Errorf("Families %v: breakdown = %+v, want %+v", tt.families, got, tt.breakdown)
+		}
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	var cfg Config
+	if got, want := cfg.Families("staticcheck"), []string{"S", "SA", "ST", "U"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Families(%q) = %v, want %v", "staticcheck", got, want)
+	}
+
+	for _, families := range [][]string{{"XX"}, {"SA1000"}} {
+		cfg := Config{Checks: map[string]CheckConfig{"staticcheck": {Families: families}}}
+		if err := cfg.validate(); err == nil {
+			t.Errorf("validate() with families %v = nil, want an error", families)
+		}
+	}
+}
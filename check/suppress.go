@@ -0,0 +1,191 @@
+package check
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"strings"
+)
+
+// ignoreDirective is the comment that suppresses findings. It is
+// followed by a comma-separated list of checks or rules, and the
+// reason the findings are suppressed:
+//
+//	//goreportcard:ignore gocyclo,misspell the parser is a big switch
+//
+// In the comments before the package clause it covers the whole file,
+// in the doc comment of a function the whole function, on its own line
+// the line that follows it, and otherwise the line it is on.
+const ignoreDirective = "//goreportcard:ignore"
+
+// The scopes an ignore directive can cover
+const (
+	ScopeLine     = "line"
+	ScopeFunction = "function"
+	ScopeFile     = "file"
+)
+
+// Suppressed is a finding that an ignore directive suppressed
+type Suppressed struct {
+	Finding
+	Reason string `json:"reason"`
+	// Scope is the part of the file the directive covers:
+	// "line", "function" or "file"
+	Scope string `json:"scope"`
+	// DirectiveLine is the line the directive is on
+	DirectiveLine int `json:"directive_line"`
+}
+
+// ignore is a single ignore directive
+type ignore struct {
+	names      []string
+	reason     string
+	scope      string
+	line       int
+	start, end int
+}
+
+// covers reports whether the directive suppresses a finding of
+// the given check and rule on a line
+func (ig ignore) covers(check, rule string, line int) bool {
+	if line < ig.start || line > ig.end {
+		return false
+	}
+
+	return contains(ig.names, check) || (rule != "" && contains(ig.names, rule))
+}
+
+// ignores maps display filenames to the ignore directives in the file
+type ignores map[string][]ignore
+
+// loadIgnores reads the ignore directives in the given files
+func loadIgnores(filenames []string) (ignores, error) {
+	igs := make(ignores)
+	for _, fn := range filenames {
+		src, err := os.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Contains(src, []byte(ignoreDirective)) {
+			continue
+		}
+
+		list := fileIgnores(fn, src)
+		if len(list) > 0 {
+			igs[displayFilename(strings.TrimPrefix(fn, "_repos/src"))] = list
+		}
+	}
+
+	return igs, nil
+}
+
+// fileIgnores parses the ignore directives in a file's source. Files
+// that do not parse have no directives.
+func fileIgnores(filename string, src []byte) []ignore {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	// doc comments of functions cover the whole function
+	funcs := make(map[*ast.CommentGroup]*ast.FuncDecl)
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok && fd.Doc != nil {
+			funcs[fd.Doc] = fd
+		}
+	}
+
+	var list []ignore
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			ig, ok := parseIgnore(c.Text)
+			if !ok {
+				continue
+			}
+
+			pos := fset.Position(c.Slash)
+			ig.line = pos.Line
+			switch fd, isDoc := funcs[cg]; {
+			case c.End() < f.Package:
+				ig.scope = ScopeFile
+				ig.start, ig.end = 1, math.MaxInt
+			case isDoc:
+				ig.scope = ScopeFunction
+				ig.start, ig.end = fset.Position(fd.Pos()).Line, fset.Position(fd.End()).Line
+			case len(bytes.TrimSpace(src[pos.Offset-pos.Column+1:pos.Offset])) == 0:
+				// on a line of its own
+				ig.scope = ScopeLine
+				ig.start, ig.end = pos.Line+1, pos.Line+1
+			default:
+				ig.scope = ScopeLine
+				ig.start, ig.end = pos.Line, pos.Line
+			}
+			list = append(list, ig)
+		}
+	}
+
+	return list
+}
+
+// parseIgnore parses the text of a comment as an ignore directive
+func parseIgnore(text string) (ignore, bool) {
+	rest, ok := strings.CutPrefix(text, ignoreDirective)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return ignore{}, false
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return ignore{}, false
+	}
+
+	return ignore{
+		names:  strings.Split(fields[0], ","),
+		reason: strings.Join(fields[1:], " "),
+	}, true
+}
+
+// apply splits the findings of a check into the ones that are
+// kept and the ones that ignore directives suppress
+func (igs ignores) apply(check string, summaries []FileSummary) ([]FileSummary, []Suppressed) {
+	if len(igs) == 0 {
+		return summaries, nil
+	}
+
+	var kept = []FileSummary{}
+	var suppressed []Suppressed
+	for _, fs := range summaries {
+		list := igs[fs.Filename]
+		if len(list) == 0 {
+			kept = append(kept, fs)
+			continue
+		}
+
+		var errs []Error
+	outer:
+		for _, e := range fs.Errors {
+			for _, ig := range list {
+				if ig.covers(check, e.Rule, e.LineNumber) {
+					suppressed = append(suppressed, Suppressed{
+						Finding:       Finding{Filename: fs.Filename, FileURL: fs.FileURL, Error: e},
+						Reason:        ig.reason,
+						Scope:         ig.scope,
+						DirectiveLine: ig.line,
+					})
+					continue outer
+				}
+			}
+			errs = append(errs, e)
+		}
+		if len(errs) > 0 {
+			fs.Errors = errs
+			kept = append(kept, fs)
+		}
+	}
+
+	return kept, suppressed
+}
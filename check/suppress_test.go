@@ -0,0 +1,70 @@
+package check
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFileIgnores(t *testing.T) {
+	src := `//goreportcard:ignore gofmt generated by hand
+
+package a
+
+//goreportcard:ignore gocyclo,misspell a big switch
+func f(x int) int {
+	return x //goreportcard:ignore ineffassign
+}
+
+func g() {
+	//goreportcard:ignore printf on purpose
+	println()
+	//goreportcard:ignored not a directive
+	//goreportcard:ignore
+}
+`
+	got := fileIgnores("a.go", []byte(src))
+	want := []ignore{
+		{names: []string{"gofmt"}, reason: "generated by hand", scope: ScopeFile, line: 1, start: 1, end: math.MaxInt},
+		{names: []string{"gocyclo", "misspell"}, reason: "a big switch", scope: ScopeFunction, line: 5, start: 6, end: 8},
+		{names: []string{"ineffassign"}, scope: ScopeLine, line: 7, start: 7, end: 7},
+		{names: []string{"printf"}, reason: "on purpose", scope: ScopeLine, line: 11, start: 12, end: 12},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fileIgnores() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunIgnore(t *testing.T) {
+	cr, err := Run("testdata/ignorerepo", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		percentage float64
+		scope      string
+		reason     string
+	}{
+		{"go_vet", 1, ScopeLine, "the directive is printed on purpose"},
+		{"misspell", 0.5, ScopeFile, "quoted from upstream docs"},
+	}
+	for _, tt := range cases {
+		var s Score
+		for _, c := range cr.Checks {
+			if c.Name == tt.name {
+				s = c
+			}
+		}
+		if s.Percentage != tt.percentage {
+			t.Errorf("%s percentage = %f, want %f", tt.name, s.Percentage, tt.percentage)
+		}
+		if len(s.Suppressed) != 1 {
+			t.Fatalf("%s suppressed %d findings, want 1", tt.name, len(s.Suppressed))
+		}
+		if got := s.Suppressed[0]; got.Scope != tt.scope || got.Reason != tt.reason || got.Filename != "testdata/ignorerepo/a.go" {
+			t.Errorf("%s suppressed = %+v, want a.go with scope %q and reason %q", tt.name, got, tt.scope, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package check
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// maxTargets is the most targets the defaults pick,
+// since packages are loaded once for every target
+const maxTargets = 6
+
+// Target is a platform and set of build tags that
+// packages are loaded and analyzed for
+type Target struct {
+	GOOS   string   `json:"goos"`
+	GOARCH string   `json:"goarch"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// String returns the target as "goos/goarch", followed by
+// its build tags in parentheses if it has any
+func (t Target) String() string {
+	s := t.GOOS + "/" + t.GOARCH
+	if len(t.Tags) > 0 {
+		s += " (" + strings.Join(t.Tags, ",") + ")"
+	}
+
+	return s
+}
+
+// env returns the environment variables that select the target's platform
+func (t Target) env() []string {
+	return []string{"GOOS=" + t.GOOS, "GOARCH=" + t.GOARCH}
+}
+
+// buildFlags returns the flags that select the target's build tags
+func (t Target) buildFlags() []string {
+	if len(t.Tags) == 0 {
+		return nil
+	}
+
+	return []string{"-tags=" + strings.Join(t.Tags, ",")}
+}
+
+// hostTarget is the platform the checks run on, without build tags
+func hostTarget() Target {
+	return Target{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+}
+
+// ports lists the platforms the go command can build for, as
+// printed by go tool dist list
+var ports = map[string][]string{
+	"aix":       {"ppc64"},
+	"android":   {"386", "amd64", "arm", "arm64"},
+	"darwin":    {"amd64", "arm64"},
+	"dragonfly": {"amd64"},
+	"freebsd":   {"386", "amd64", "arm", "arm64"},
+	"illumos":   {"amd64"},
+	"ios":       {"amd64", "arm64"},
+	"js":        {"wasm"},
+	"linux":     {"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le", "mipsle", "ppc64", "ppc64le", "riscv64", "s390x"},
+	"netbsd":    {"386", "amd64", "arm", "arm64"},
+	"openbsd":   {"386", "amd64", "arm", "arm64", "ppc64", "riscv64"},
+	"plan9":     {"386", "amd64", "arm"},
+	"solaris":   {"amd64"},
+	"wasip1":    {"wasm"},
+	"windows":   {"386", "amd64", "arm64"},
+}
+
+// knownOS and knownArch are the values of GOOS and GOARCH that
+// build constraints and file names can refer to, as in go/build
+var (
+	knownOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+		"linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+	}
+	knownArch = []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips",
+		"mipsle", "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le",
+		"riscv", "riscv64", "s390", "s390x", "sparc", "sparc64", "wasm",
+	}
+)
+
+// ParsePlatform parses a platform such as "linux/amd64"
+func ParsePlatform(s string) (Target, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok {
+		return Target{}, fmt.Errorf("platform %q is not of the form goos/goarch", s)
+	}
+	if !contains(ports[goos], goarch) {
+		return Target{}, fmt.Errorf("unsupported platform %q", s)
+	}
+
+	return Target{GOOS: goos, GOARCH: goarch}, nil
+}
+
+// parseTags parses a comma-separated set of build tags
+func parseTags(s string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if x, err := constraint.Parse("//go:build " + tag); err != nil {
+			return nil, fmt.Errorf("invalid build tag %q", tag)
+		} else if _, ok := x.(*constraint.TagExpr); !ok {
+			return nil, fmt.Errorf("invalid build tag %q", tag)
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return tags, nil
+}
+
+// Matrix returns the targets for every combination of platforms and
+// tag sets, each of which is a comma-separated list of build tags.
+// Without platforms the host platform is used, and every platform
+// is always analyzed without tags as well.
+func Matrix(platforms, tagSets []string) ([]Target, error) {
+	var bases []Target
+	for _, p := range platforms {
+		t, err := ParsePlatform(p)
+		if err != nil {
+			return nil, err
+		}
+		bases = append(bases, t)
+	}
+	if len(bases) == 0 {
+		bases = []Target{hostTarget()}
+	}
+
+	sets := [][]string{nil}
+	for _, s := range tagSets {
+		tags, err := parseTags(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) > 0 {
+			sets = append(sets, tags)
+		}
+	}
+
+	var targets []Target
+	seen := make(map[string]bool)
+	for _, b := range bases {
+		for _, tags := range sets {
+			t := Target{GOOS: b.GOOS, GOARCH: b.GOARCH, Tags: tags}
+			if !seen[t.String()] {
+				seen[t.String()] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// defaultTargets returns the targets the files in filenames are
+// written for: the host platform and the other platforms that their
+// build constraints and names refer to. Other build tags are left
+// out, since they can exclude each other; tag sets are opt-in
+// through the configuration file.
+func defaultTargets(filenames []string) []Target {
+	host := hostTarget()
+	oses := make(map[string]bool)
+	arches := make(map[string]bool)
+	for _, fn := range filenames {
+		goos, goarch := nameConstraint(fn)
+		if goos != "" {
+			oses[goos] = true
+		}
+		if goarch != "" {
+			arches[goarch] = true
+		}
+
+		for _, tag := range buildTags(fn) {
+			switch {
+			case contains(knownOS, tag):
+				oses[tag] = true
+			case contains(knownArch, tag):
+				arches[tag] = true
+			}
+		}
+	}
+
+	targets := []Target{host}
+	for _, goos := range sortedKeys(oses) {
+		if goos == host.GOOS || len(ports[goos]) == 0 {
+			continue
+		}
+		goarch := host.GOARCH
+		if !contains(ports[goos], goarch) {
+			goarch = ports[goos][0]
+		}
+		targets = append(targets, Target{GOOS: goos, GOARCH: goarch})
+	}
+	for _, goarch := range sortedKeys(arches) {
+		if goarch == host.GOARCH {
+			continue
+		}
+		switch {
+		case contains(ports[host.GOOS], goarch):
+			targets = append(targets, Target{GOOS: host.GOOS, GOARCH: goarch})
+		case contains(ports["linux"], goarch):
+			targets = append(targets, Target{GOOS: "linux", GOARCH: goarch})
+		}
+	}
+
+	if len(targets) > maxTargets {
+		targets = targets[:maxTargets]
+	}
+
+	return targets
+}
+
+// nameConstraint returns the GOOS and GOARCH that the name
+// of a file such as "a_linux_amd64.go" restricts it to
+func nameConstraint(filename string) (goos, goarch string) {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return "", ""
+	}
+
+	last := parts[len(parts)-1]
+	if len(parts) >= 3 && contains(knownOS, parts[len(parts)-2]) && contains(knownArch, last) {
+		return parts[len(parts)-2], last
+	}
+	if contains(knownOS, last) {
+		return last, ""
+	}
+	if contains(knownArch, last) {
+		return "", last
+	}
+
+	return "", ""
+}
+
+// buildTags returns the tags that the build constraints
+// of a file refer to. Files that do not parse have none.
+func buildTags(filename string) []string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	var tags []string
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			tags = appendTags(tags, expr)
+		}
+	}
+
+	return tags
+}
+
+// appendTags appends the tags a build constraint refers to
+func appendTags(tags []string, x constraint.Expr) []string {
+	switch x := x.(type) {
+	case *constraint.TagExpr:
+		return append(tags, x.Tag)
+	case *constraint.NotExpr:
+		return appendTags(tags, x.X)
+	case *constraint.AndExpr:
+		return appendTags(appendTags(tags, x.X), x.Y)
+	case *constraint.OrExpr:
+		return appendTags(appendTags(tags, x.X), x.Y)
+	}
+
+	return tags
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
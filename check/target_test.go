@@ -0,0 +1,184 @@
+package check
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestNameConstraint(t *testing.T) {
+	cases := []struct {
+		filename     string
+		goos, goarch string
+	}{
+		{"a.go", "", ""},
+		{"linux.go", "", ""},
+		{"a_linux.go", "linux", ""},
+		{"a_windows_test.go", "windows", ""},
+		{"a_arm64.go", "", "arm64"},
+		{"dir/a_darwin_arm64.go", "darwin", "arm64"},
+		{"a_other.go", "", ""},
+	}
+
+	for _, tt := range cases {
+		goos, goarch := nameConstraint(tt.filename)
+		if goos != tt.goos || goarch != tt.goarch {
+			t.Errorf("nameConstraint(%q) = %q, %q, want %q, %q", tt.filename, goos, goarch, tt.goos, tt.goarch)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	cases := []struct {
+		platforms []string
+		tags      []string
+		want      []string
+		err       bool
+	}{
+		{[]string{"linux/amd64", "windows/arm64"}, nil, []string{"linux/amd64", "windows/arm64"}, false},
+		{[]string{"linux/amd64"}, []string{"integration", "e2e, slow", ""}, []string{"linux/amd64", "linux/amd64 (integration)", "linux/amd64 (e2e,slow)"}, false},
+		{nil, nil, []string{runtime.GOOS + "/" + runtime.GOARCH}, false},
+		{[]string{"linux"}, nil, nil, true},
+		{[]string{"windows/mips"}, nil, nil, true},
+		{nil, []string{"a b"}, nil, true},
+	}
+
+	for _, tt := range cases {
+		targets, err := Matrix(tt.platforms, tt.tags)
+		if (err != nil) != tt.err {
+			t.Errorf("Matrix(%q, %q) err = %v, want error %t", tt.platforms, tt.tags, err, tt.err)
+			continue
+		}
+		var got []string
+		for _, t := range targets {
+			got = append(got, t.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Matrix(%q, %q) = %q, want %q", tt.platforms, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultTargets(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/platforms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for _, target := range defaultTargets(filenames) {
+		got[target.String()] = true
+		if len(target.Tags) > 0 {
+			t.Errorf("defaultTargets() has %s, want no build tags", target)
+		}
+	}
+	if host := hostTarget(); !got[host.String()] {
+		t.Errorf("defaultTargets() = %v, want %s", got, host)
+	}
+	for _, goos := range []string{"linux", "windows"} {
+		var found bool
+		for s := range got {
+			if len(s) > len(goos) && s[:len(goos)+1] == goos+"/" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("defaultTargets() = %v, want a %s target", got, goos)
+		}
+	}
+}
+
+func TestAnalyzeTargets(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/platforms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := Matrix([]string{"linux/amd64", "windows/amd64"}, []string{"integration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, summaries, err := GoVet{Filenames: filenames, Driver: NewDriver("testdata/platforms", targets...)}.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"testdata/platforms/extra.go":            {"linux/amd64 (integration)", "windows/amd64 (integration)"},
+		"testdata/platforms/greeting_linux.go":   {"linux/amd64", "linux/amd64 (integration)"},
+		"testdata/platforms/greeting_windows.go": {"windows/amd64", "windows/amd64 (integration)"},
+	}
+	got := make(map[string][]string)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			got[fs.Filename] = e.Targets
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("targets of findings = %v, want %v", got, want)
+	}
+}
+
+func TestConfigTargets(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/platforms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := hostTarget().String()
+
+	cases := []struct {
+		cfg  Config
+		want []string
+	}{
+		{Config{Platforms: []string{"linux/amd64"}, Tags: []string{"integration"}}, []string{"linux/amd64", "linux/amd64 (integration)"}},
+		{Config{Tags: []string{"integration", "broken"}}, []string{host + " (integration)", host + " (broken)"}},
+	}
+
+	for _, tt := range cases {
+		got := make(map[string]bool)
+		for _, target := range tt.cfg.targets(filenames) {
+			got[target.String()] = true
+		}
+		for _, want := range tt.want {
+			if !got[want] {
+				t.Errorf("targets(%q, %q) = %v, want %s", tt.cfg.Platforms, tt.cfg.Tags, got, want)
+			}
+		}
+	}
+}
+
+func TestIllTypedTargets(t *testing.T) {
+	filenames, _, err := GoFiles("testdata/platforms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linux := Target{GOOS: "linux", GOARCH: "amd64"}
+	broken := Target{GOOS: "linux", GOARCH: "amd64", Tags: []string{"broken"}}
+	brokenWindows := Target{GOOS: "windows", GOARCH: "amd64", Tags: []string{"broken"}}
+	cases := []struct {
+		targets []Target
+		want    []Target
+	}{
+		{[]Target{linux, broken}, []Target{linux}},
+		{[]Target{broken, linux}, []Target{linux}},
+		// without a target that type-checks, the first one is kept
+		{[]Target{broken, brokenWindows}, []Target{broken}},
+		{[]Target{broken}, []Target{broken}},
+	}
+
+	for _, tt := range cases {
+		d := NewDriver("testdata/platforms", tt.targets...)
+		if _, _, err := (GoVet{Filenames: filenames, Driver: d}).Percentage(); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []Target
+		for _, l := range d.loads {
+			got = append(got, l.target)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("targets loaded for %v = %v, want %v", tt.targets, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cognitive
+
+// flat is easy to read, however many cases its switch has
+func flat(n int) string {
+	switch n {
+	case 1:
+		return "one"
+	case 2:
+		return "two"
+	case 3:
+		return "three"
+	case 4:
+		return "four"
+	case 5:
+		return "five"
+	case 6:
+		return "six"
+	case 7:
+		return "seven"
+	case 8:
+		return "eight"
+	}
+	return ""
+}
+
+// nested is hard to read, with few paths through it
+func nested(xs [][]int, ok bool) int {
+	total := 0
+	for _, row := range xs {
+		for _, x := range row {
+			if x > 0 && ok {
+				if x%2 == 0 {
+					total += x
+				} else {
+					total -= x
+				}
+			} else if x < 0 || !ok {
+				continue
+			}
+		}
+	}
+	return total
+}
+
+// closure nests its loop in a function literal
+func closure(xs []int) func() int {
+	return func() int {
+	outer:
+		for _, x := range xs {
+			if x > 0 && x < 10 || x == 42 {
+				break outer
+			}
+		}
+		return 0
+	}
+}
@@ -0,0 +1,14 @@
+package configrepo
+
+func classify(a, b int) int {
+	if a > 0 && b > 0 {
+		return 1
+	}
+	if a < 0 || b < 0 {
+		return -1
+	}
+	if a == b {
+		return 0
+	}
+	return 2
+}
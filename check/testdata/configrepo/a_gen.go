@@ -0,0 +1,3 @@
+package configrepo
+
+var generated = 1
@@ -0,0 +1,3 @@
+package mocks
+
+var mocked = 1
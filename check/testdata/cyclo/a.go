@@ -0,0 +1,41 @@
+package cyclo
+
+func simple(a int) int {
+	if a > 0 {
+		return a
+	}
+	return -a
+}
+
+func complex(a, b, c int) int {
+	n := 0
+	for i := 0; i < a; i++ {
+		if i%2 == 0 && i%3 == 0 {
+			n++
+		} else if i%5 == 0 || i%7 == 0 {
+			n--
+		}
+		switch {
+		case b > c:
+			n += b
+		case b < c:
+			n += c
+		case b == 0:
+			n++
+		default:
+			n--
+		}
+		if n > 100 || n < -100 {
+			return n
+		}
+		for j := 0; j < b && j < c; j++ {
+			if j > i {
+				break
+			}
+		}
+	}
+	if a > 1 && b > 1 && c > 1 {
+		n *= 2
+	}
+	return n
+}
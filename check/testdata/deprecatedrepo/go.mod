@@ -0,0 +1,3 @@
+module example.com/deprecatedrepo
+
+go 1.21
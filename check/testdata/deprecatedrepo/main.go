@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"example.com/deprecatedrepo/old"
+)
+
+func main() {
+	b, _ := ioutil.ReadFile("go.mod")
+	fmt.Println(strings.Title(string(b)))
+
+	c := &old.Counter{Count: old.Limit}
+	c.Reset()
+	fmt.Println(old.Sum(1, 2), old.Add(1, 2), c.N)
+}
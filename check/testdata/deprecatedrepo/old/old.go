@@ -0,0 +1,38 @@
+// Package old has deprecated identifiers
+package old
+
+// Sum adds up numbers.
+//
+// Deprecated: Use Add instead,
+// which does not allocate.
+func Sum(n ...int) int {
+	return Add(n...)
+}
+
+// Add adds up numbers
+func Add(n ...int) int {
+	var sum int
+	for _, x := range n {
+		sum += x
+	}
+	return sum
+}
+
+// Counter counts
+type Counter struct {
+	// Deprecated: Use N instead.
+	Count int
+	N     int
+}
+
+// Reset sets the counter to zero.
+//
+// Deprecated: Assign a zero Counter instead.
+func (c *Counter) Reset() {
+	c.N = Sum()
+}
+
+// Limit is the largest count.
+//
+// Deprecated: Counters have no limit.
+const Limit = 100
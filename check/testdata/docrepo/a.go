@@ -0,0 +1,33 @@
+// Package docrepo has some documented identifiers
+package docrepo
+
+// Documented is documented
+func Documented() {}
+
+func Undocumented() {}
+
+func unexported() {}
+
+// T is documented
+type T struct{}
+
+func (T) Method() {}
+
+type t struct{}
+
+func (t) Method() {}
+
+// Group of constants
+const (
+	A = 1
+	B = 2
+)
+
+var (
+	// C is documented
+	C = 3
+	D = 4 // a comment at the end of the line is not a doc comment
+	E = 5
+)
+
+type U int
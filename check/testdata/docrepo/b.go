@@ -0,0 +1,3 @@
+package docrepo
+
+var F, g = 1, 2
@@ -0,0 +1,23 @@
+package dupl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Count adds up the sizes of the names that are not blank
+func Count(names []string) int {
+	n := 0
+	for j, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		n += len(name) * j
+	}
+	return n
+}
+
+// Print prints the count
+func Print(names []string) {
+	fmt.Println(Count(names))
+}
@@ -0,0 +1,3 @@
+module example.com/errcheckrepo
+
+go 1.21
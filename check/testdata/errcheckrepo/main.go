@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func write(name string) (int, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	b.WriteString("hello")
+	fmt.Println(b.String())
+	f.WriteString(b.String())
+	return f.Write([]byte("\n"))
+}
+
+func main() {
+	write("out.txt")
+	_, _ = write("out.txt")
+	remove := os.Remove
+	go remove("out.txt")
+}
@@ -0,0 +1,3 @@
+// generated documentation index, written by hand
+
+package generated
@@ -0,0 +1,12 @@
+//goreportcard:ignore misspell quoted from upstream docs
+
+// Package ignorerepo is obivous
+package ignorerepo
+
+import "fmt"
+
+// Greet greets someone
+func Greet(name string) {
+	//goreportcard:ignore printf the directive is printed on purpose
+	fmt.Println("hello %s", name)
+}
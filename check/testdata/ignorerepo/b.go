@@ -0,0 +1,4 @@
+package ignorerepo
+
+// Wave is obivous too
+func Wave() {}
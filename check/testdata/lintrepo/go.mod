@@ -0,0 +1,3 @@
+module example.com/lintrepo
+
+go 1.21
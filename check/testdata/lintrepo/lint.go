@@ -0,0 +1,12 @@
+// Package lintrepo has some style mistakes for golint
+package lintrepo
+
+func Exported() {}
+
+type counter int
+
+func (this counter) add(n int) int {
+	// golint is not confident enough about this one to report it
+	n += 1
+	return int(this) + n
+}
@@ -0,0 +1,3 @@
+module example.com/multimod/empty
+
+go 1.21
@@ -0,0 +1,9 @@
+// Package lib belongs to the root module
+package lib
+
+import "fmt"
+
+// Hello says hello
+func Hello() {
+	fmt.Println("hello")
+}
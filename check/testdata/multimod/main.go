@@ -0,0 +1,8 @@
+// Package main is the root module of a repository with nested modules
+package main
+
+import "example.com/multimod/lib"
+
+func main() {
+	lib.Hello()
+}
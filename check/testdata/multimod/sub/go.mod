@@ -0,0 +1,3 @@
+module example.com/multimod/sub
+
+go 1.21
@@ -0,0 +1,5 @@
+// Package sub is a module nested in the root module
+package sub
+
+// Recieve has a misspelling in its name's comment: recieve
+func Recieve() {}
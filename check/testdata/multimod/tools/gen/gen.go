@@ -0,0 +1,5 @@
+// Package gen is excluded by the root configuration
+package gen
+
+// Recieve has a misspelling in its name's comment: recieve
+func Recieve() {}
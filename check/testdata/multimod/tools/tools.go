@@ -0,0 +1,5 @@
+// Package tools is a nested module that uses the root configuration
+package tools
+
+// Version is the version of the tools
+const Version = "v0.1.0"
@@ -0,0 +1,9 @@
+//go:build broken
+
+package platforms
+
+// greeting is declared for every platform already,
+// so the package does not type-check with this tag
+func greeting() string {
+	return "broken"
+}
@@ -0,0 +1,10 @@
+//go:build integration
+
+package platforms
+
+import "fmt"
+
+// Extra is only built with the integration tag
+func Extra() {
+	fmt.Printf("%s\n", 1)
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !windows
+
+package platforms
+
+func greeting() string {
+	return "hello"
+}
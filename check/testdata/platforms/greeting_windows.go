@@ -0,0 +1,7 @@
+package platforms
+
+import "fmt"
+
+func greeting() string {
+	return fmt.Sprintf("hello from %d", "windows")
+}
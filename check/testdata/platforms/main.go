@@ -0,0 +1,9 @@
+// Package platforms has code for more than one platform
+package platforms
+
+import "fmt"
+
+// Hello says hello
+func Hello() {
+	fmt.Println(greeting())
+}
@@ -0,0 +1,6 @@
+/*
+ * Copyright 2024 Example Authors
+ * SPDX-License-Identifier: Apache-2.0 OR MIT
+ */
+
+package reuse
@@ -0,0 +1,4 @@
+// Copyright (c) 2024 Example Authors
+// SPDX-License-Identifier: GPL-3.0-only
+
+package reuse
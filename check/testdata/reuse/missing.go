@@ -0,0 +1,2 @@
+// Package reuse has license headers
+package reuse
@@ -0,0 +1,5 @@
+// SPDX-FileCopyrightText: 2024 Example Authors
+// SPDX-License-Identifier: MIT
+
+// Package reuse has license headers
+package reuse
@@ -0,0 +1,5 @@
+// SPDX-FileCopyrightText: 2024 Other Authors
+// SPDX-License-Identifier: ISC
+
+// Package other is licensed differently
+package other
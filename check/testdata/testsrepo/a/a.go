@@ -0,0 +1,16 @@
+// Package a has tests and examples
+package a
+
+// Foo is referred to from an example
+func Foo() {}
+
+// Bar is not referred to from tests
+func Bar() {}
+
+// T is a type with methods
+type T struct{}
+
+// Baz is referred to from a test
+func (t *T) Baz() int { return 1 }
+
+func unexported() {}
@@ -0,0 +1,10 @@
+package a
+
+import "testing"
+
+func TestBaz(t *testing.T) {
+	unexported()
+	if (&T{}).Baz() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,8 @@
+package a_test
+
+import "example.com/testsrepo/a"
+
+func ExampleFoo() {
+	a.Foo()
+	// Output:
+}
@@ -0,0 +1,5 @@
+// Package b has no tests
+package b
+
+// Qux is not referred to from tests
+func Qux() {}
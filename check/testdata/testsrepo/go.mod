@@ -0,0 +1,3 @@
+module example.com/testsrepo
+
+go 1.21
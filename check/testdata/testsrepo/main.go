@@ -0,0 +1,11 @@
+package main
+
+import (
+	"example.com/testsrepo/a"
+	"example.com/testsrepo/b"
+)
+
+func main() {
+	a.Foo()
+	b.Qux()
+}
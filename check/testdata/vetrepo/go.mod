@@ -0,0 +1,3 @@
+module example.com/vetrepo
+
+go 1.21
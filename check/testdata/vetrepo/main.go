@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+type point struct {
+	x, y int
+}
+
+func nilDeref(p *point) int {
+	if p == nil {
+		return p.x
+	}
+	return p.y
+}
+
+func shadowed() error {
+	err := errors.New("outer")
+	if len(os.Args) > 1 {
+		err := fmt.Errorf("inner")
+		fmt.Println(err)
+	}
+	return err
+}
+
+func lostWrite(p point) {
+	p.x = 1
+}
+
+func sorted(a []int) {
+	sort.Slice(&a, func(i, j int) bool { return a[i] < a[j] })
+}
+
+func main() {
+	fmt.Println(nilDeref(&point{}), shadowed())
+	lostWrite(point{})
+	sorted(nil)
+}
@@ -0,0 +1,3 @@
+module example.com/vulnrepo
+
+go 1.24
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	fmt.Println(shout(os.Args[0]))
+}
+
+func shout(s string) string {
+	return strings.ToUpper(s)
+}
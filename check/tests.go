@@ -0,0 +1,325 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// The parts of the score of the tests check, which add up to 1
+const (
+	testedPackagesWeight   = .5
+	referencedFuncsWeight  = .4
+	exampledPackagesWeight = .1
+)
+
+// Tests is the check for the presence of tests. It only looks at
+// the test files of packages and what they refer to, and never runs
+// the tests.
+type Tests struct {
+	Dir       string
+	Filenames []string
+	Driver    *Driver
+
+	// stats is what PercentageContext measured,
+	// which score scores findings against
+	stats testStats
+}
+
+// Name returns the name of the display name of the command
+func (t *Tests) Name() string {
+	return "tests"
+}
+
+// Weight returns the weight this check has in the overall average
+func (t *Tests) Weight() float64 {
+	return .10
+}
+
+// Percentage returns the weighted share of packages with tests, of
+// exported functions referred to from tests, and of packages with examples
+func (t *Tests) Percentage() (float64, []FileSummary, error) {
+	return t.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (t *Tests) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	stats, err := t.measure(ctx)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+	t.stats = stats
+
+	var failed = []FileSummary{}
+	index := make(map[string]int)
+	add := func(filename string, e Error) {
+		filename = strings.TrimPrefix(filename, "_repos/src")
+		dfn := displayFilename(filename)
+		i, ok := index[dfn]
+		if !ok {
+			i = len(failed)
+			index[dfn] = i
+			failed = append(failed, FileSummary{Filename: dfn, FileURL: fileURL(filename)})
+		}
+		failed[i].Errors = append(failed[i].Errors, e)
+	}
+
+	for _, p := range stats.packages {
+		if !p.tested {
+			add(p.filename, ruleError("untested-package", p.line, 0, fmt.Sprintf("warning: package %s has no _test.go files (tests)", p.name)))
+		}
+		if p.exported > 0 && !p.exampled {
+			add(p.filename, ruleError("missing-example", p.line, 0, fmt.Sprintf("warning: package %s has no Example functions (tests)", p.name)))
+		}
+	}
+	for _, f := range stats.funcs {
+		if !f.referenced {
+			add(f.filename, ruleError("untested-func", f.line, 0, fmt.Sprintf("warning: exported function %s is not referred to from tests (tests)", f.name)))
+		}
+	}
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].Filename < failed[j].Filename
+	})
+	for _, fs := range failed {
+		sort.SliceStable(fs.Errors, func(i, j int) bool {
+			return fs.Errors[i].LineNumber < fs.Errors[j].LineNumber
+		})
+	}
+
+	return stats.score(failed), failed, nil
+}
+
+// score scores the findings that are left of the ones Percentage
+// reported, against the packages and functions it measured
+func (t *Tests) score(filenames []string, failed []FileSummary) (float64, error) {
+	return t.stats.score(failed), nil
+}
+
+// testPackage is what the tests check measured of a package
+type testPackage struct {
+	name string
+	// filename and line are where the package clause of
+	// the package's first file is, where findings about
+	// the whole package are reported
+	filename string
+	line     int
+	// exported is the number of exported functions
+	exported int
+	tested   bool
+	exampled bool
+}
+
+// testFunc is what the tests check measured of an exported function
+type testFunc struct {
+	name       string
+	filename   string
+	line       int
+	referenced bool
+}
+
+// testStats is what the tests check measured of all packages
+type testStats struct {
+	packages []testPackage
+	funcs    []testFunc
+}
+
+// score weighs the share of packages with tests, of exported functions
+// referred to from tests and of packages with examples, counting the
+// findings that are left in failed as the ones that are missing
+func (s testStats) score(failed []FileSummary) float64 {
+	counts := make(map[string]int)
+	for _, fs := range failed {
+		for _, e := range fs.Errors {
+			counts[e.Rule]++
+		}
+	}
+
+	var withExported int
+	for _, p := range s.packages {
+		if p.exported > 0 {
+			withExported++
+		}
+	}
+
+	return testedPackagesWeight*ratio(len(s.packages)-counts["untested-package"], len(s.packages)) +
+		referencedFuncsWeight*ratio(len(s.funcs)-counts["untested-func"], len(s.funcs)) +
+		exampledPackagesWeight*ratio(withExported-counts["missing-example"], withExported)
+}
+
+// ratio returns n out of total, which is 1 if there is nothing to count
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+
+	return float64(n) / float64(total)
+}
+
+// measure finds the packages with tests and examples, and the exported
+// functions that test files refer to, among the packages of filenames
+func (t *Tests) measure(ctx context.Context) (testStats, error) {
+	pkgs, err := t.Driver.Packages(ctx)
+	if err != nil {
+		return testStats{}, err
+	}
+
+	checked := make(map[string]string, len(t.Filenames))
+	for _, fn := range t.Filenames {
+		abs, err := filepath.Abs(fn)
+		if err != nil {
+			return testStats{}, err
+		}
+		checked[abs] = fn
+	}
+
+	// positions of the declarations test files refer to, and the
+	// packages with test files and examples, by import path
+	referenced := make(map[string]bool)
+	tested := make(map[string]bool)
+	exampled := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return testStats{}, err
+		}
+
+		path := strings.TrimSuffix(pkg.PkgPath, "_test")
+		for _, f := range pkg.Syntax {
+			if !strings.HasSuffix(pkg.Fset.File(f.Pos()).Name(), "_test.go") {
+				continue
+			}
+			tested[path] = true
+			for _, decl := range f.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil && strings.HasPrefix(fd.Name.Name, "Example") {
+					exampled[path] = true
+				}
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if obj := pkg.TypesInfo.Uses[id]; obj != nil && obj.Pos().IsValid() {
+						referenced[positionKey(pkg.Fset.Position(obj.Pos()))] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	var stats testStats
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return testStats{}, err
+		}
+
+		// test variants of packages are measured
+		// as part of the package they test
+		if pkg.ID != pkg.PkgPath {
+			continue
+		}
+
+		p, funcs := measurePackage(pkg, checked, referenced)
+		if p.filename == "" {
+			continue
+		}
+		p.tested = tested[pkg.PkgPath]
+		p.exampled = exampled[pkg.PkgPath]
+		stats.packages = append(stats.packages, p)
+		stats.funcs = append(stats.funcs, funcs...)
+	}
+
+	return stats, nil
+}
+
+// measurePackage returns the exported functions of the files of pkg that
+// are being checked, and whether the declarations in referenced refer to them
+func measurePackage(pkg *packages.Package, checked map[string]string, referenced map[string]bool) (testPackage, []testFunc) {
+	var p testPackage
+	var funcs []testFunc
+	for _, f := range pkg.Syntax {
+		fn, ok := checked[pkg.Fset.File(f.Pos()).Name()]
+		if !ok {
+			continue
+		}
+		if p.filename == "" || fn < p.filename {
+			p.name = f.Name.Name
+			p.filename = fn
+			p.line = pkg.Fset.Position(f.Package).Line
+		}
+		if f.Name.Name == "main" {
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || !fd.Name.IsExported() || !exportedRecv(fd) {
+				continue
+			}
+			pos := pkg.Fset.Position(fd.Name.Pos())
+			funcs = append(funcs, testFunc{
+				name:       funcName(fd),
+				filename:   fn,
+				line:       pos.Line,
+				referenced: referenced[positionKey(pos)],
+			})
+		}
+	}
+	p.exported = len(funcs)
+
+	return p, funcs
+}
+
+// exportedRecv reports whether a function has no
+// receiver or a receiver of an exported type
+func exportedRecv(fd *ast.FuncDecl) bool {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return true
+	}
+
+	name := recvName(fd.Recv.List[0].Type)
+	return name != "" && ast.IsExported(name)
+}
+
+// recvName returns the name of the type of a receiver
+func recvName(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.StarExpr:
+		return recvName(x.X)
+	case *ast.ParenExpr:
+		return recvName(x.X)
+	case *ast.IndexExpr:
+		return recvName(x.X)
+	case *ast.IndexListExpr:
+		return recvName(x.X)
+	}
+
+	return ""
+}
+
+// funcName returns the name of a function, qualified
+// by the type of its receiver if it is a method
+func funcName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name + "()"
+	}
+
+	return recvName(fd.Recv.List[0].Type) + "." + fd.Name.Name + "()"
+}
+
+// positionKey identifies a declaration across the variants of a
+// package that are compiled with and without its tests
+func positionKey(pos token.Position) string {
+	return fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column)
+}
+
+// Description returns the description of Tests
+func (t *Tests) Description() string {
+	return `Measures how much of the code has tests, without running them. Half of the score is the share of packages with <code>_test.go</code> files, 40% the share of exported functions that tests refer to, and 10% the share of packages with exported functions that have <code>Example</code> functions.`
+}
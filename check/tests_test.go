@@ -0,0 +1,81 @@
+package check
+
+import (
+	"go/parser"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTests(t *testing.T) {
+	dir := "testdata/testsrepo"
+	filenames, _, err := GoFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &Tests{Dir: dir, Filenames: filenames, Driver: NewDriver(dir)}
+	p, summaries, err := tc.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one of three packages has tests, two of four exported
+	// functions are referred to from tests, and one of two
+	// packages with exported functions has examples
+	want := .5*1/3 + .4*2/4 + .1*1/2
+	if math.Abs(p-want) > 1e-9 {
+		t.Errorf("Percentage() = %f, want %f", p, want)
+	}
+
+	got := make(map[string][]string)
+	for _, fs := range summaries {
+		for _, e := range fs.Errors {
+			got[fs.Filename] = append(got[fs.Filename], e.Rule)
+		}
+	}
+	wantRules := map[string][]string{
+		"testdata/testsrepo/a/a.go":  {"untested-func"},
+		"testdata/testsrepo/b/b.go":  {"untested-package", "missing-example", "untested-func"},
+		"testdata/testsrepo/main.go": {"untested-package"},
+	}
+	if !reflect.DeepEqual(got, wantRules) {
+		t.Errorf("rules of findings = %v, want %v", got, wantRules)
+	}
+
+	// findings left after suppressions are scored against what
+	// Percentage measured, without loading the packages again
+	tc.Driver = nil
+	if s, err := tc.score(filenames, summaries); err != nil || math.Abs(s-want) > 1e-9 {
+		t.Errorf("score() = %f, %v, want %f", s, err, want)
+	}
+	want += .4 * 1 / 4
+	if s, err := tc.score(filenames, summaries[1:]); err != nil || math.Abs(s-want) > 1e-9 {
+		t.Errorf("score() without the findings of %s = %f, %v, want %f", summaries[0].Filename, s, err, want)
+	}
+}
+
+func TestRecvName(t *testing.T) {
+	cases := []struct {
+		recv string
+		want string
+	}{
+		{"T", "T"},
+		{"*T", "T"},
+		{"(*T)", "T"},
+		{"*(T)", "T"},
+		{"T[K]", "T"},
+		{"*T[K, V]", "T"},
+		{"[]T", ""},
+	}
+
+	for _, tt := range cases {
+		x, err := parser.ParseExpr(tt.recv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := recvName(x); got != tt.want {
+			t.Errorf("recvName(%s) = %q, want %q", tt.recv, got, tt.want)
+		}
+	}
+}
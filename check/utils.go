@@ -2,62 +2,80 @@ package check
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
 )
 
 var (
-	skipDirs       = []string{"Godeps", "vendor", "third_party", "testdata"}
-	skipSuffixes   = []string{".pb.go", ".pb.gw.go", ".generated.go", "bindata.go", "_string.go"}
-	skipFirstLines = []string{"code generated", "generated", "autogenerated", "@generated", "code autogenerated", "auto-generated"}
+	skipDirs     = []string{"Godeps", "vendor", "third_party", "testdata"}
+	skipSuffixes = []string{".pb.go", ".pb.gw.go", ".generated.go", "bindata.go", "_string.go"}
 )
 
-func addSkipDirs(params []string) []string {
-	for _, dir := range skipDirs {
-		params = append(params, fmt.Sprintf("--skip=%s", dir))
-	}
-	return params
+// generatedRx matches the comment that marks generated files,
+// see https://go.dev/s/generatedcode
+var generatedRx = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// SkippedFile is a file or directory that is not checked
+type SkippedFile struct {
+	Filename string `json:"filename"`
+	Reason   string `json:"reason"`
 }
 
 // GoFiles returns a slice of Go filenames
-// in a given directory.
-func GoFiles(dir string) (filenames, skipped []string, err error) {
+// in a given directory, leaving out the ones
+// matching any of the exclude patterns.
+// It also returns the files and directories
+// it left out, and why.
+func GoFiles(dir string, exclude ...string) (filenames []string, skipped []SkippedFile, err error) {
 	visit := func(fp string, fi os.FileInfo, err error) error {
-		for _, skip := range skipDirs {
-			if strings.Contains(fp, fmt.Sprintf("/%s/", skip)) {
-				return nil
-			}
-		}
 		if err != nil {
 			fmt.Println(err) // can't walk here,
 			return nil       // but continue walking elsewhere
 		}
+		rel, err := filepath.Rel(dir, fp)
+		if err != nil || rel == "." {
+			return nil
+		}
+		if fi.IsDir() && contains(skipDirs, fi.Name()) {
+			skipped = append(skipped, SkippedFile{fp, fmt.Sprintf("%s directory", fi.Name())})
+			return filepath.SkipDir
+		}
+		if fi.IsDir() && isModuleRoot(fp) {
+			skipped = append(skipped, SkippedFile{fp, "separate module"})
+			return filepath.SkipDir
+		}
+		if pattern := excludePattern(exclude, rel); pattern != "" {
+			skipped = append(skipped, SkippedFile{fp, fmt.Sprintf("excluded by %q", pattern)})
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if fi.IsDir() {
 			return nil // not a file.  ignore.
 		}
 		fiName := fi.Name()
-		for _, skip := range skipSuffixes {
-			if strings.HasSuffix(fiName, skip) {
-				skipped = append(skipped, fp)
-				return nil
-			}
+		if reason := skipReason(fp); reason != "" {
+			skipped = append(skipped, SkippedFile{fp, reason})
+			return nil
 		}
 		ext := filepath.Ext(fiName)
 		if ext != ".go" {
 			return nil
 		}
 
-		if autoGenerated(fp) {
-			skipped = append(skipped, fp)
-			return nil
-		}
-
 		filenames = append(filenames, fp)
 
 		return nil
@@ -68,72 +86,84 @@ func GoFiles(dir string) (filenames, skipped []string, err error) {
 	return filenames, skipped, err
 }
 
-// RenameFiles renames the provided filenames to have a ".grc.bk" extension,
-// so they will not be considered in future checks.
-func RenameFiles(names []string) (err error) {
-	for i := range names {
-		tmpErr := os.Rename(names[i], names[i]+".grc.bk")
-		if tmpErr != nil {
-			// save this error, but still continue with other files
-			err = tmpErr
+// skipReason returns why the Go file at fp is not checked,
+// or "" if it is
+func skipReason(fp string) string {
+	for _, suffix := range skipSuffixes {
+		if strings.HasSuffix(fp, suffix) {
+			return fmt.Sprintf("name ends in %s", suffix)
 		}
 	}
-
-	return err
-}
-
-// RevertFiles removes the ".grc.bk" extension from files
-func RevertFiles(names []string) (err error) {
-	for i := range names {
-		tmpErr := os.Rename(names[i]+".grc.bk", names[i])
-		if tmpErr != nil {
-			// save this error, but still continue with other files
-			err = tmpErr
-		}
+	if filepath.Ext(fp) != ".go" {
+		return ""
+	}
+	if marker := generatedMarker(fp); marker != "" {
+		return fmt.Sprintf("generated (%s)", marker)
 	}
 
-	return err
+	return ""
 }
 
-// lineCount returns the number of lines in a given file
+// lineCount returns the number of lines in a given file,
+// counted as newlines the way wc -l counts them
 func lineCount(filepath string) (int, error) {
-	out, err := exec.Command("wc", "-l", filepath).Output()
+	b, err := os.ReadFile(filepath)
 	if err != nil {
 		return 0, err
 	}
 
-	// wc output is like: 999 filename.go
-	count, err := strconv.Atoi(strings.Split(strings.TrimSpace(string(out)), " ")[0])
-	if err != nil {
-		return 0, fmt.Errorf("could not count lines: %v", err)
+	return bytes.Count(b, []byte{'\n'}), nil
+}
+
+// eachFile parses the files in filenames with mode and calls fn with
+// each of them, until ctx is done or fn fails. Files that do not parse
+// are skipped by the checks that measure their syntax, since gofmt
+// reports them already.
+func eachFile(ctx context.Context, filenames []string, mode parser.Mode, fn func(filename string, src []byte, fset *token.FileSet, f *ast.File) error) error {
+	for _, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filename, src, mode)
+		if err != nil {
+			continue
+		}
+		if err := fn(filename, src, fset, f); err != nil {
+			return err
+		}
 	}
 
-	return count, nil
+	return nil
 }
 
-// determine whether the Go file was auto-generated
-func autoGenerated(fp string) bool {
-	file, err := os.Open(fp)
-	if err != nil {
-		fmt.Println(err)
-		return false
-	}
-	defer file.Close()
-
-	// read first line of file and determine if it might
-	// be auto-generated
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	line := strings.ToLower(scanner.Text())
-	commentStyles := []string{"// ", "//", "/* ", "/*"}
-	for _, skip := range skipFirstLines {
-		for i := range commentStyles {
-			if strings.HasPrefix(line, commentStyles[i]) && strings.HasPrefix(line[len(commentStyles[i]):], skip) {
-				return true
+// generatedMarker returns the comment that marks the Go file at fp
+// as generated, or "" if there is none. Following the Go convention,
+// the marker is a line comment that comes before the package clause.
+func generatedMarker(fp string) string {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, fp, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if f == nil {
+		return ""
+	}
+
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if generatedRx.MatchString(c.Text) {
+				return c.Text
 			}
 		}
 	}
-	return false
+
+	return ""
 }
 
 // Error contains the line number and the reason for
@@ -141,6 +171,62 @@ func autoGenerated(fp string) bool {
 type Error struct {
 	LineNumber  int    `json:"line_number"`
 	ErrorString string `json:"error_string"`
+	// Column, EndLine and EndColumn are set when the
+	// check reports them, and are 1-based like LineNumber
+	Column    int `json:"column,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+	EndColumn int `json:"end_column,omitempty"`
+	// Rule is the ID of the rule the error was reported for,
+	// and Severity, Category and DocURL describe that rule
+	Rule     string   `json:"rule,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	Category Category `json:"category,omitempty"`
+	DocURL   string   `json:"doc_url,omitempty"`
+	// Function is set for errors about a measurement
+	// of a whole function, such as its complexity
+	Function *FunctionMetric `json:"function,omitempty"`
+	// Targets lists the platforms and build tags the error was
+	// found for, if it was not found for all that were analyzed
+	Targets []string `json:"targets,omitempty"`
+	// Related lists the other places the error is about,
+	// such as the other copies of duplicated code
+	Related []Location `json:"related,omitempty"`
+}
+
+// Location is a range of lines in a file
+type Location struct {
+	Filename   string `json:"filename"`
+	FileURL    string `json:"file_url"`
+	LineNumber int    `json:"line_number"`
+	EndLine    int    `json:"end_line"`
+}
+
+// FunctionMetric is a measurement of a single function, such as its
+// cyclomatic complexity, or of a whole file, in which case Name is empty
+type FunctionMetric struct {
+	Name string `json:"name"`
+	// Metric is what Value measures, such as "statements"
+	Metric    string `json:"metric,omitempty"`
+	Value     int    `json:"value"`
+	Threshold int    `json:"threshold"`
+	// Over is how far Value is over Threshold
+	Over      int `json:"over"`
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+	// Contributions breaks Value down into what contributed
+	// to it, for metrics that add up parts of the function
+	Contributions []Contribution `json:"contributions,omitempty"`
+}
+
+// Contribution is a part of a function that adds to a metric
+type Contribution struct {
+	Line int `json:"line"`
+	// Reason is the construct that contributed, such as "if"
+	Reason    string `json:"reason"`
+	Increment int    `json:"increment"`
+	// Nesting is how much of Increment is due to the
+	// construct being nested in others
+	Nesting int `json:"nesting,omitempty"`
 }
 
 // FileSummary contains the filename, location of the file
@@ -149,26 +235,41 @@ type FileSummary struct {
 	Filename string  `json:"filename"`
 	FileURL  string  `json:"file_url"`
 	Errors   []Error `json:"errors"`
+	// Diff is a unified diff of the changes a check would
+	// make to the file, if it suggests any
+	Diff string `json:"diff,omitempty"`
 }
 
-// AddError adds an Error to FileSummary
+// AddError adds an Error to FileSummary from a line of output
+// like "file.go:12:5: message (rule)", where the column is optional
 func (fs *FileSummary) AddError(out string) error {
 	s := strings.SplitN(out, ":", 2)
 	msg := strings.SplitAfterN(s[1], ":", 3)[2]
 
-	e := Error{ErrorString: msg}
 	ls := strings.Split(s[1], ":")
 	ln, err := strconv.Atoi(ls[0])
 	if err != nil {
 		return fmt.Errorf("AddError: could not parse %q - %v", out, err)
 	}
-	e.LineNumber = ln
+	col, _ := strconv.Atoi(ls[1])
 
-	fs.Errors = append(fs.Errors, e)
+	fs.Errors = append(fs.Errors, ruleError(outputRule(msg), ln, col, msg))
 
 	return nil
 }
 
+// outputRule returns the rule named in parentheses at the end of
+// a message, the way gometalinter and staticcheck report it
+func outputRule(msg string) string {
+	msg = strings.TrimSpace(msg)
+	i := strings.LastIndex(msg, "(")
+	if i < 0 || !strings.HasSuffix(msg, ")") {
+		return ""
+	}
+
+	return msg[i+1 : len(msg)-1]
+}
+
 func displayFilename(filename string) string {
 	sp := strings.Split(filename, "@")
 	if len(sp) < 2 {
@@ -294,23 +395,29 @@ func makeFilename(fn string) string {
 	return fn
 }
 
-func getFileSummaryMap(out *bufio.Scanner, dir string) (map[string]FileSummary, error) {
+// getFileSummaryMap reads the output of a tool into a map of display
+// filenames to summaries. Output about files that are not checked is
+// left out, and those files are returned along with why.
+func getFileSummaryMap(out *bufio.Scanner, dir string) (map[string]FileSummary, []SkippedFile, error) {
 	fsMap := make(map[string]FileSummary)
-outer:
+	var skipped []SkippedFile
+	reasons := make(map[string]string)
 	for out.Scan() {
 		filename := strings.Split(out.Text(), ":")[0]
 		if !strings.Contains(filename, dir) {
 			filename = filepath.Join(dir, filename)
 		}
 
-		for _, skip := range skipSuffixes {
-			if strings.HasSuffix(filename, skip) {
-				continue outer
+		reason, ok := reasons[filename]
+		if !ok {
+			reason = skipReason(filename)
+			reasons[filename] = reason
+			if reason != "" {
+				skipped = append(skipped, SkippedFile{filename, reason})
 			}
 		}
-
-		if autoGenerated(filename) {
-			continue outer
+		if reason != "" {
+			continue
 		}
 
 		filename = strings.TrimPrefix(filename, "_repos/src")
@@ -324,54 +431,32 @@ outer:
 		}
 		err := fs.AddError(out.Text())
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		fsMap[dfn] = fs
 	}
 
-	return fsMap, nil
+	return fsMap, skipped, nil
 }
 
 // GoTool runs a given go command (for example gofmt, go tool vet)
 // on a directory
 func GoTool(dir string, filenames, command []string) (float64, []FileSummary, error) {
-	var enabledCheck = command[0]
-	if command[0] == "gometalinter" {
-		enabledCheck = command[len(command)-1]
-	}
-
-	// temporary disabling of misspell as it's the slowest
-	// command right now
-	if strings.Contains(enabledCheck, "misspell") && len(filenames) > 300 {
-		log.Println("disabling misspell on large repo...")
-		return 1, []FileSummary{}, nil
-	}
-
-	if strings.Contains(enabledCheck, "ineffassign") && len(filenames) > 100 {
-		log.Println("disabling ineffassign on large repo...")
-		return 1, []FileSummary{}, nil
-	}
+	return GoToolContext(context.Background(), dir, filenames, command)
+}
 
+// GoToolContext is like GoTool, but kills the command once ctx is done
+func GoToolContext(ctx context.Context, dir string, filenames, command []string) (float64, []FileSummary, error) {
+	var enabledCheck = command[0]
 	params := command[1:]
-
-	if command[0] == "gometalinter" {
-		params = addSkipDirs(params)
-	}
-
 	switch {
 	case strings.Contains(enabledCheck, "cyclo"):
 		params = append(params, dir)
-	case strings.Contains(enabledCheck, "staticcheck"):
-		params[len(params)-1] = "./..."
 	default:
 		params = append(params, dir+"/...")
 	}
 
-	cmd := exec.Command(command[0], params...)
-
-	if strings.Contains(enabledCheck, "staticcheck") {
-		cmd.Dir = dir
-	}
+	cmd := exec.CommandContext(ctx, command[0], params...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -379,6 +464,9 @@ func GoTool(dir string, filenames, command []string) (float64, []FileSummary, er
 	}
 
 	err = cmd.Start()
+	if errors.Is(err, exec.ErrNotFound) {
+		return 0, []FileSummary{}, &SkipError{Reason: command[0] + " is not installed"}
+	}
 	if err != nil {
 		return 0, []FileSummary{}, err
 	}
@@ -390,7 +478,9 @@ func GoTool(dir string, filenames, command []string) (float64, []FileSummary, er
 	// a map of filename to FileSummary
 	var failed = []FileSummary{}
 
-	fsMap, err := getFileSummaryMap(out, dir)
+	// files that are not checked are left out of the
+	// file names as well, so there is no need to report them
+	fsMap, _, err := getFileSummaryMap(out, dir)
 	if err != nil {
 		return 0, []FileSummary{}, err
 	}
@@ -399,11 +489,22 @@ func GoTool(dir string, filenames, command []string) (float64, []FileSummary, er
 		return 0, []FileSummary{}, err
 	}
 
-	for _, v := range fsMap {
-		failed = append(failed, v)
+	// tools run on the whole directory, so leave out
+	// the files that we were not asked about
+	names := make(map[string]bool, len(filenames))
+	for _, fn := range filenames {
+		names[displayFilename(strings.TrimPrefix(filepath.Clean(fn), "_repos/src"))] = true
+	}
+	for dfn, v := range fsMap {
+		if names[dfn] {
+			failed = append(failed, v)
+		}
 	}
 
 	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return 0, failed, ctx.Err()
+	}
 	if exitErr, ok := err.(*exec.ExitError); ok {
 		// The program has exited with an exit code != 0
 
@@ -416,10 +517,21 @@ func GoTool(dir string, filenames, command []string) (float64, []FileSummary, er
 		}
 	}
 
+	p, err := passRatio(filenames, failed)
+	if err != nil {
+		return 0, failed, err
+	}
+
+	return p, failed, nil
+}
+
+// passRatio returns the fraction of filenames without failures. A single
+// file is scored by the fraction of its lines without failures instead.
+func passRatio(filenames []string, failed []FileSummary) (float64, error) {
 	if len(filenames) == 1 {
 		lc, err := lineCount(filenames[0])
 		if err != nil {
-			return 0, failed, err
+			return 0, err
 		}
 
 		var errors int
@@ -427,8 +539,8 @@ func GoTool(dir string, filenames, command []string) (float64, []FileSummary, er
 			errors = len(failed[0].Errors)
 		}
 
-		return float64(lc-errors) / float64(lc), failed, nil
+		return float64(lc-errors) / float64(lc), nil
 	}
 
-	return float64(len(filenames)-len(failed)) / float64(len(filenames)), failed, nil
+	return float64(len(filenames)-len(failed)) / float64(len(filenames)), nil
 }
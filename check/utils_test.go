@@ -1,7 +1,11 @@
 package check
 
 import (
+	"bufio"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -15,12 +19,59 @@ func TestGoFiles(t *testing.T) {
 		t.Errorf("GoFiles(%q) = %v, want %v", "testdata/testfiles/", files, want)
 	}
 
-	wantSkipped := []string{"testdata/testfiles/a.pb.go", "testdata/testfiles/a.pb.gw.go"}
+	wantSkipped := []SkippedFile{
+		{"testdata/testfiles/Godeps", "Godeps directory"},
+		{"testdata/testfiles/a.pb.go", "name ends in .pb.go"},
+		{"testdata/testfiles/a.pb.gw.go", "name ends in .pb.gw.go"},
+		{"testdata/testfiles/testdata", "testdata directory"},
+		{"testdata/testfiles/third_party", "third_party directory"},
+		{"testdata/testfiles/vendor", "vendor directory"},
+	}
 	if !reflect.DeepEqual(skipped, wantSkipped) {
 		t.Errorf("GoFiles(%q) skipped = %v, want %v", "testdata/testfiles/", skipped, wantSkipped)
 	}
 }
 
+func TestGeneratedMarker(t *testing.T) {
+	cases := []struct {
+		file string
+		want string
+	}{
+		{"license.go", "// Code generated by stringer -type=Color; DO NOT EDIT."},
+		{"marker.go", "// Code generated by protoc-gen-go. DO NOT EDIT."},
+		{"loose.go", ""},
+		{"after.go", ""},
+		{"block.go", ""},
+		{"trailing.go", ""},
+	}
+
+	for _, tt := range cases {
+		if got := generatedMarker("testdata/generated/" + tt.file); got != tt.want {
+			t.Errorf("generatedMarker(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestGetFileSummaryMap(t *testing.T) {
+	out := bufio.NewScanner(strings.NewReader(strings.Join([]string{
+		"testdata/generated/marker.go:4:1: exported const should have comment (golint)",
+		"testdata/generated/loose.go:3:1: package comment should be of the form (golint)",
+		"testdata/generated/marker.go:5:1: exported const should have comment (golint)",
+	}, "\n")))
+
+	fsMap, skipped, err := getFileSummaryMap(out, "testdata/generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fsMap["testdata/generated/loose.go"]; len(fsMap) != 1 || !ok {
+		t.Errorf("getFileSummaryMap files = %v, want only loose.go", fsMap)
+	}
+	want := []SkippedFile{{"testdata/generated/marker.go", "generated (// Code generated by protoc-gen-go. DO NOT EDIT.)"}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("getFileSummaryMap skipped = %v, want %v", skipped, want)
+	}
+}
+
 var goToolTests = []struct {
 	name      string
 	dir       string
@@ -31,22 +82,6 @@ var goToolTests = []struct {
 	wantErr   bool
 }{
 	{"go vet", "testdata/testfiles", []string{"testdata/testfiles/a.go", "testdata/testfiles/b.go", "testdata/testfiles/c.go"}, []string{"go", "vet"}, 1, []FileSummary{}, false},
-	{
-		"staticcheck",
-		"testdata/testfiles/",
-		[]string{"testdata/testfiles/a.go", "testdata/testfiles/b.go", "testdata/testfiles/c.go", "testdata/testfiles/d.go"},
-		[]string{"staticcheck", "./..."},
-		0.75,
-		[]FileSummary{
-			{
-				Filename: "testdata/testfiles/d.go", FileURL: "",
-				Errors: []Error{
-					{LineNumber: 8, ErrorString: " func foo is unused (U1000)"},
-					{LineNumber: 10, ErrorString: " should use time.Until instead of t.Sub(time.Now()) (S1024)"}},
-			},
-		},
-		false,
-	},
 }
 
 func TestGoTool(t *testing.T) {
@@ -113,3 +148,45 @@ func TestDisplayFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestSkipUnparseable(t *testing.T) {
+	cases := []struct {
+		files []string
+		check func(filenames []string) Check
+	}{
+		{[]string{"testdata/cyclo/a.go"}, func(fns []string) Check { return GoCyclo{Filenames: fns} }},
+		{[]string{"testdata/cognitive/a.go"}, func(fns []string) Check { return Cognitive{Filenames: fns, Over: 10} }},
+		{[]string{"testdata/length/a.go"}, func(fns []string) Check { return Length{Filenames: fns} }},
+		{[]string{"testdata/dupl/a.go", "testdata/dupl/b.go"}, func(fns []string) Check { return &Dupl{Filenames: fns, Over: 20} }},
+	}
+
+	broken := brokenFile(t)
+	for _, tt := range cases {
+		_, want, err := tt.check(tt.files).Percentage()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the file that does not parse is left to gofmt
+		c := tt.check(append(tt.files, broken))
+		_, got, err := c.Percentage()
+		if err != nil {
+			t.Errorf("%s: Percentage() with a file that does not parse: %v", c.Name(), err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: findings with a file that does not parse = %+v, want %+v", c.Name(), got, want)
+		}
+	}
+}
+
+// brokenFile returns the name of a file that does not
+// parse, with a syntax error before its imports end
+func brokenFile(t *testing.T) string {
+	fn := filepath.Join(t.TempDir(), "broken.go")
+	if err := os.WriteFile(fn, []byte("package broken\n\nimport \"fmt\n\nfunc broken() {\n\tif {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fn
+}
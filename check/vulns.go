@@ -0,0 +1,340 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/vuln/scan"
+)
+
+// Vulns is the check for known vulnerabilities in the dependencies of
+// a module, looked up in a local copy of the Go vulnerability database.
+// Only vulnerable functions that the module's code can reach count
+// against the score.
+type Vulns struct {
+	Dir       string
+	Filenames []string
+	// DB is the directory of the vulnerability database, a mirror of
+	// https://vuln.go.dev or a directory of OSV entries. The check is
+	// skipped without it, and never goes to the network.
+	DB string
+	// Target is the platform and build tags the
+	// module is analyzed for, the host if unset
+	Target Target
+}
+
+// Name returns the name of the display name of the command
+func (v Vulns) Name() string {
+	return "vulncheck"
+}
+
+// Weight returns the weight this check has in the overall average
+func (v Vulns) Weight() float64 {
+	return .10
+}
+
+// Percentage returns 1 divided by one more than the number
+// of vulnerabilities the module's code reaches
+func (v Vulns) Percentage() (float64, []FileSummary, error) {
+	return v.PercentageContext(context.Background())
+}
+
+// PercentageContext is like Percentage, but stops once ctx is done
+func (v Vulns) PercentageContext(ctx context.Context) (float64, []FileSummary, error) {
+	if v.DB == "" {
+		return 0, []FileSummary{}, &SkipError{Reason: "no vulnerability database configured"}
+	}
+	gomod := filepath.Join(v.Dir, "go.mod")
+	data, err := os.ReadFile(gomod)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, []FileSummary{}, &SkipError{Reason: "no go.mod file"}
+	}
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+	mf, err := modfile.ParseLax(gomod, data, nil)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	entries, findings, err := v.scan(ctx)
+	if err != nil {
+		return 0, []FileSummary{}, err
+	}
+
+	// the most precise level every vulnerability was found at,
+	// since module and package findings are also emitted for the
+	// vulnerabilities that are found to be called
+	levels := make(map[string]int)
+	for _, f := range findings {
+		levels[f.OSV] = max(levels[f.OSV], f.level())
+	}
+
+	checked := make(map[string]bool, len(v.Filenames))
+	for _, fn := range v.Filenames {
+		checked[filepath.Clean(fn)] = true
+	}
+
+	var failed = []FileSummary{}
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	add := func(filename string, e Error) {
+		key := fmt.Sprintf("%s:%d:%s", filename, e.LineNumber, e.ErrorString)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		filename = strings.TrimPrefix(filename, "_repos/src")
+		dfn := displayFilename(filename)
+		i, ok := index[dfn]
+		if !ok {
+			i = len(failed)
+			index[dfn] = i
+			failed = append(failed, FileSummary{Filename: dfn, FileURL: fileURL(filename)})
+		}
+		failed[i].Errors = append(failed[i].Errors, e)
+	}
+
+	for _, f := range findings {
+		if f.level() < levels[f.OSV] || len(f.Trace) == 0 {
+			continue
+		}
+
+		vuln := f.OSV
+		if s := entries[f.OSV].Summary; s != "" {
+			vuln += ": " + s
+		}
+		if f.FixedVersion != "" {
+			vuln += ", fixed in " + f.FixedVersion
+		}
+		sink := f.Trace[0]
+
+		var e Error
+		filename := gomod
+		switch f.level() {
+		case symbolLevel:
+			caller, pos := f.caller(mf.Module.Mod.Path)
+			line, col := requireLine(mf, sink.Module), 0
+			if pos != nil && checked[filepath.Join(v.Dir, filepath.FromSlash(pos.Filename))] {
+				filename = filepath.Join(v.Dir, filepath.FromSlash(pos.Filename))
+				line, col = pos.Line, pos.Column
+			}
+			e = ruleError("vulnerable-symbol", line, col, fmt.Sprintf("warning: %s calls %s, which is affected by %s (vulncheck)", caller, sink.symbol(), vuln))
+		case packageLevel:
+			e = ruleError("vulnerable-package", requireLine(mf, sink.Module), 0, fmt.Sprintf("warning: package %s is imported but none of its functions affected by %s are called (vulncheck)", sink.Package, vuln))
+		default:
+			e = ruleError("vulnerable-module", requireLine(mf, sink.Module), 0, fmt.Sprintf("warning: module %s is required but none of its packages affected by %s are imported (vulncheck)", sink.module(), vuln))
+		}
+		e.DocURL = "https://pkg.go.dev/vuln/" + f.OSV
+		add(filename, e)
+	}
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].Filename < failed[j].Filename
+	})
+	for _, fs := range failed {
+		sort.SliceStable(fs.Errors, func(i, j int) bool {
+			return fs.Errors[i].LineNumber < fs.Errors[j].LineNumber
+		})
+	}
+
+	p, err := v.score(v.Filenames, failed)
+	return p, failed, err
+}
+
+// score returns 1 divided by one more than the number of
+// vulnerabilities that reachable findings are left for
+func (v Vulns) score(filenames []string, failed []FileSummary) (float64, error) {
+	reached := make(map[string]bool)
+	for _, fs := range failed {
+		for _, e := range fs.Errors {
+			if e.Rule == "vulnerable-symbol" {
+				reached[e.DocURL] = true
+			}
+		}
+	}
+
+	return 1 / float64(1+len(reached)), nil
+}
+
+// scan runs govulncheck on the module against the database,
+// and returns the OSV entries it reported by ID and its findings
+func (v Vulns) scan(ctx context.Context) (map[string]vulnEntry, []vulnFinding, error) {
+	db, err := filepath.Abs(v.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(db); err != nil {
+		return nil, nil, fmt.Errorf("could not open vulnerability database: %v", err)
+	}
+
+	target := v.Target
+	if target.GOOS == "" {
+		target = hostTarget()
+	}
+	args := []string{"-db", "file://" + filepath.ToSlash(db), "-format", "json", "-C", v.Dir}
+	if len(target.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(target.Tags, ","))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := scan.Command(ctx, append(args, "./...")...)
+	cmd.Stdin = strings.NewReader("")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), target.env()...)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, fmt.Errorf("govulncheck: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	entries := make(map[string]vulnEntry)
+	var findings []vulnFinding
+	dec := json.NewDecoder(&stdout)
+	for {
+		var msg vulnMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse govulncheck output: %v", err)
+		}
+		if msg.OSV != nil {
+			entries[msg.OSV.ID] = *msg.OSV
+		}
+		if msg.Finding != nil {
+			findings = append(findings, *msg.Finding)
+		}
+	}
+
+	return entries, findings, nil
+}
+
+// requireLine returns the line of go.mod that brings in a
+// module, or its go directive for the standard library
+func requireLine(mf *modfile.File, path string) int {
+	if path == "stdlib" && mf.Go != nil {
+		return mf.Go.Syntax.Start.Line
+	}
+	for _, r := range mf.Require {
+		if r.Mod.Path == path {
+			return r.Syntax.Start.Line
+		}
+	}
+
+	return 1
+}
+
+// vulnMessage is the part of a message of govulncheck's
+// JSON output that the check uses
+type vulnMessage struct {
+	OSV     *vulnEntry   `json:"osv"`
+	Finding *vulnFinding `json:"finding"`
+}
+
+// vulnEntry is the part of an OSV entry that the check uses
+type vulnEntry struct {
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+}
+
+// The levels a vulnerability can be found at, from least to most precise
+const (
+	moduleLevel = iota + 1
+	packageLevel
+	symbolLevel
+)
+
+// vulnFinding is a vulnerability govulncheck found. Its trace
+// starts at the vulnerable module, package or function and, for
+// functions, goes up the call stack to the module's code.
+type vulnFinding struct {
+	OSV          string      `json:"osv"`
+	FixedVersion string      `json:"fixed_version"`
+	Trace        []vulnFrame `json:"trace"`
+}
+
+// level returns how precisely the vulnerability was found
+func (f vulnFinding) level() int {
+	switch {
+	case len(f.Trace) == 0:
+		return 0
+	case f.Trace[0].Function != "":
+		return symbolLevel
+	case f.Trace[0].Package != "":
+		return packageLevel
+	}
+
+	return moduleLevel
+}
+
+// caller returns the function of the module at path that is
+// closest to the vulnerable function in the trace, and the
+// position of its call on the way to the vulnerable function
+func (f vulnFinding) caller(path string) (string, *vulnPosition) {
+	for _, fr := range f.Trace[1:] {
+		if fr.Module == path {
+			return fr.symbol(), fr.Position
+		}
+	}
+
+	return "the module", nil
+}
+
+// vulnFrame is an entry in the trace of a finding
+type vulnFrame struct {
+	Module   string        `json:"module"`
+	Version  string        `json:"version"`
+	Package  string        `json:"package"`
+	Function string        `json:"function"`
+	Receiver string        `json:"receiver"`
+	Position *vulnPosition `json:"position"`
+}
+
+// symbol returns the qualified name of the function of the frame
+func (fr vulnFrame) symbol() string {
+	name := fr.Function
+	if fr.Receiver != "" {
+		name = strings.TrimPrefix(fr.Receiver, "*") + "." + name
+	}
+
+	return fr.Package + "." + name
+}
+
+// module returns the module of the frame with its version
+func (fr vulnFrame) module() string {
+	if fr.Version == "" {
+		return fr.Module
+	}
+
+	return fr.Module + "@" + fr.Version
+}
+
+// vulnPosition is a position in a trace, with a filename
+// that is relative to the root of the module it is in
+type vulnPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// Description returns the description of Vulns
+func (v Vulns) Description() string {
+	return `Looks up the dependencies of the module in a local copy of the <a href="https://go.dev/security/vuln/">Go vulnerability database</a>, the way <a href="https://pkg.go.dev/golang.org/x/vuln/cmd/govulncheck">govulncheck</a> does. Only vulnerable functions that the code calls lower the score.`
+}
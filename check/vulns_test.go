@@ -0,0 +1,71 @@
+package check
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVulns(t *testing.T) {
+	v := Vulns{
+		Dir:       "testdata/vulnrepo",
+		Filenames: []string{"testdata/vulnrepo/main.go"},
+		DB:        "testdata/vulndb",
+	}
+	p, failed, err := v.Percentage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only GO-2099-0001 is reachable
+	if p != .5 {
+		t.Errorf("Percentage() = %f, want %f", p, .5)
+	}
+
+	type finding struct {
+		filename string
+		line     int
+		rule     string
+		docURL   string
+	}
+	var got []finding
+	for _, fs := range failed {
+		for _, e := range fs.Errors {
+			got = append(got, finding{fs.Filename, e.LineNumber, e.Rule, e.DocURL})
+		}
+	}
+	want := []finding{
+		// archive/tar is not imported at all, os.Chdir is not
+		// called and strings.ToUpper is called from shout
+		{"testdata/vulnrepo/go.mod", 3, "vulnerable-module", "https://pkg.go.dev/vuln/GO-2099-0003"},
+		{"testdata/vulnrepo/go.mod", 3, "vulnerable-package", "https://pkg.go.dev/vuln/GO-2099-0002"},
+		{"testdata/vulnrepo/main.go", 14, "vulnerable-symbol", "https://pkg.go.dev/vuln/GO-2099-0001"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if failed[0].Errors[0].Severity != SeverityInfo || failed[1].Errors[0].Severity != SeverityError {
+		t.Errorf("severities = %s, %s, want reachable findings to be errors and the others info", failed[0].Errors[0].Severity, failed[1].Errors[0].Severity)
+	}
+}
+
+func TestVulnsSkipped(t *testing.T) {
+	cases := []struct {
+		dir, db string
+	}{
+		{"testdata/vulnrepo", ""},
+		// GOPATH-style repositories without go.mod
+		{"testdata/testfiles", "testdata/vulndb"},
+	}
+
+	for _, tt := range cases {
+		_, _, err := Vulns{Dir: tt.dir, DB: tt.db}.Percentage()
+		var skip *SkipError
+		if !errors.As(err, &skip) {
+			t.Errorf("%s with database %q: Percentage() error = %v, want a SkipError", tt.dir, tt.db, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -16,6 +17,13 @@ var (
 	verbose = flag.Bool("v", false, "Verbose output")
 	th      = flag.Float64("t", 0, "Threshold of failure command")
 	jsn     = flag.Bool("j", false, "JSON output. The binary will always exit with code 0")
+	sev     = flag.String("severity", "", "Only show issues of at least this severity: info, warning or error")
+	cat     = flag.String("category", "", "Only show issues in these comma-separated categories, such as bug,style")
+	rule    = flag.String("rule", "", "Only show issues of these comma-separated rules, such as printf,gofmt")
+	group   = flag.String("group", "file", "Group verbose output by file, rule, category or severity")
+	plats   = flag.String("platforms", "", "Analyze for these comma-separated platforms, such as linux/amd64,windows/amd64")
+	tags    = flag.String("tags", "", "Also analyze with these space-separated sets of comma-separated build tags, such as \"integration e2e,slow\"")
+	vulndb  = flag.String("vulndb", os.Getenv("GRC_VULN_DB"), "Directory of a local mirror of the Go vulnerability database to look dependencies up in")
 )
 
 // dotPrintf fills in the blank space between two strings with dots. The total
@@ -31,14 +39,107 @@ func dotPrintf(fullLen int, lfStr, rtFmtStr string, args ...interface{}) {
 	fmt.Printf("%s %s %s\n", lfStr, strings.Repeat(".", dotLen), rtStr)
 }
 
+// splitList splits a comma-separated flag value
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
+
+// targetsSuffix lists the targets an issue was found for,
+// if it was not found for all of them
+func targetsSuffix(e check.Error) string {
+	if len(e.Targets) == 0 {
+		return ""
+	}
+
+	return " [" + strings.Join(e.Targets, "; ") + "]"
+}
+
+// printContributions prints what contributed to the
+// measurement an issue is about, if it breaks it down
+func printContributions(e check.Error) {
+	if e.Function == nil {
+		return
+	}
+
+	for _, c := range e.Function.Contributions {
+		nesting := ""
+		if c.Nesting > 0 {
+			nesting = fmt.Sprintf(" (nesting %d)", c.Nesting)
+		}
+		fmt.Printf("\t\t\tLine %d: +%d %s%s\n", c.Line, c.Increment, c.Reason, nesting)
+	}
+}
+
+// newFilter builds a filter for the issues shown from the flags
+func newFilter() (check.Filter, error) {
+	var f check.Filter
+	if *sev != "" {
+		s, err := check.ParseSeverity(*sev)
+		if err != nil {
+			return f, err
+		}
+		f.MinSeverity = s
+	}
+	for _, c := range splitList(*cat) {
+		f.Categories = append(f.Categories, check.Category(c))
+	}
+	f.Rules = splitList(*rule)
+
+	return f, nil
+}
+
+// printGroups prints the issues of a check grouped by anything but file
+func printGroups(summaries []check.FileSummary) {
+	groups, err := check.GroupBy(summaries, *group)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, g := range groups {
+		fmt.Printf("\t%s\n", g.Key)
+		for _, f := range g.Findings {
+			fmt.Printf("\t\t%s:%d: %s%s\n", f.Filename, f.LineNumber, f.ErrorString, targetsSuffix(f.Error))
+		}
+	}
+}
+
 func main() {
 	flag.Parse()
 
-	result, err := check.Run(*dir, true)
+	filter, err := newFilter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := check.GroupBy(nil, *group); err != nil {
+		log.Fatal(err)
+	}
+
+	opts := check.Options{VulnDB: *vulndb}
+	if *plats != "" || *tags != "" {
+		opts.Targets, err = check.Matrix(splitList(*plats), strings.Fields(*tags))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	result, err := check.RunOptions(context.Background(), *dir, true, opts)
 	if err != nil {
 		log.Fatalf("Fatal error checking %s: %s", *dir, err.Error())
 	}
 
+	for i := range result.Checks {
+		result.Checks[i].FileSummaries = filter.Apply(result.Checks[i].FileSummaries)
+	}
+	for _, m := range result.Modules {
+		for i := range m.Checks {
+			m.Checks[i].FileSummaries = filter.Apply(m.Checks[i].FileSummaries)
+		}
+	}
+
 	if *jsn {
 		marshalledResults, _ := json.Marshal(result)
 		fmt.Println(string(marshalledResults))
@@ -48,15 +149,54 @@ func main() {
 	dotPrintf(24, "Grade", "%s %.1f%%", result.Grade, result.Average*100)
 	dotPrintf(24, "Files", "%d", result.Files)
 	dotPrintf(24, "Issues", "%d", result.Issues)
+	if l := result.License; l != nil && l.SPDXID != "" {
+		osi := ""
+		if l.OSIApproved {
+			osi = ", OSI approved"
+		}
+		dotPrintf(24, "License", "%s (%.0f%%%s)", l.SPDXID, l.Confidence*100, osi)
+	}
+	if len(result.Targets) > 1 {
+		dotPrintf(24, "Targets", "%s", strings.Join(result.Targets, ", "))
+	}
+
+	for _, m := range result.Modules {
+		dotPrintf(24, "Module "+m.Dir, "%s %.1f%% (%d files, %d issues)", m.Grade, m.Average*100, m.Files, m.Issues)
+	}
 
 	for _, c := range result.Checks {
+		if c.TimedOut {
+			dotPrintf(24, c.Name, "timed out")
+			continue
+		}
+		if c.Skipped != "" {
+			dotPrintf(24, c.Name, "skipped")
+			if *verbose {
+				fmt.Printf("\t%s\n", c.Skipped)
+			}
+			continue
+		}
 		dotPrintf(24, c.Name, "%d%%", int64(c.Percentage*100))
-		if *verbose && len(c.FileSummaries) > 0 {
+		for _, b := range c.Breakdown {
+			fmt.Printf("\t%s (%s): %d\n", b.Family, b.Title, b.Count)
+		}
+		if *verbose && len(c.FileSummaries) > 0 && *group != "file" {
+			printGroups(c.FileSummaries)
+		} else if *verbose && len(c.FileSummaries) > 0 {
 			for _, f := range c.FileSummaries {
 				fmt.Printf("\t%s\n", f.Filename)
 				for _, e := range f.Errors {
-					fmt.Printf("\t\tLine %d: %s\n", e.LineNumber, e.ErrorString)
+					fmt.Printf("\t\tLine %d: %s%s\n", e.LineNumber, e.ErrorString, targetsSuffix(e))
+					printContributions(e)
 				}
+				if f.Diff != "" {
+					fmt.Printf("\t\t%s\n", strings.ReplaceAll(strings.TrimSuffix(f.Diff, "\n"), "\n", "\n\t\t"))
+				}
+			}
+		}
+		if *verbose {
+			for _, s := range c.Suppressed {
+				fmt.Printf("\tsuppressed %s:%d: %s (%s)\n", s.Filename, s.LineNumber, s.ErrorString, s.Reason)
 			}
 		}
 	}
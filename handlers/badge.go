@@ -9,9 +9,10 @@ import (
 	"github.com/gojp/goreportcard/check"
 )
 
-// BadgeHandler handles fetching the badge images
-func BadgeHandler(w http.ResponseWriter, r *http.Request, db *badger.DB, repo string) {
-	resp, err := newChecksResp(db, repo, false)
+// BadgeHandler handles fetching the badge images, checking repos
+// that are not cached yet against the vulnerability database at vulnDB
+func BadgeHandler(w http.ResponseWriter, r *http.Request, db *badger.DB, repo, vulnDB string) {
+	resp, err := newChecksResp(r.Context(), db, repo, false, vulnDB)
 
 	// See: http://shields.io/#styles
 	style := r.URL.Query().Get("style")
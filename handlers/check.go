@@ -17,8 +17,9 @@ const (
 	RepoPrefix string = "repos-"
 )
 
-// CheckHandler handles the request for checking a repo
-func CheckHandler(w http.ResponseWriter, r *http.Request, db *badger.DB) {
+// CheckHandler handles the request for checking a repo against
+// the vulnerability database at vulnDB
+func CheckHandler(w http.ResponseWriter, r *http.Request, db *badger.DB, vulnDB string) {
 	w.Header().Set("Content-Type", "application/json")
 
 	repo := download.Clean(r.FormValue("repo"))
@@ -36,7 +37,7 @@ func CheckHandler(w http.ResponseWriter, r *http.Request, db *badger.DB) {
 	log.Printf("Checking repo %q...", repo)
 
 	forceRefresh := r.Method != "GET" // if this is a GET request, try to fetch from cached version in badger first
-	_, err = newChecksResp(db, repo, forceRefresh)
+	_, err = newChecksResp(r.Context(), db, repo, forceRefresh, vulnDB)
 	if err != nil {
 		log.Println("ERROR: from newChecksResp:", err)
 		http.Error(w, "Could not analyze the repository: "+err.Error(), http.StatusBadRequest)
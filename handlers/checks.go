@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -66,21 +67,28 @@ func getFromCache(db *badger.DB, repo string) (checksResp, error) {
 }
 
 type checksResp struct {
-	Checks               []check.Score `json:"checks"`
-	Average              float64       `json:"average"`
-	Grade                check.Grade   `json:"grade"`
-	Files                int           `json:"files"`
-	Issues               int           `json:"issues"`
-	Repo                 string        `json:"repo"`
-	Version              string        `json:"version"`
-	ResolvedRepo         string        `json:"resolvedRepo"`
-	LastRefresh          time.Time     `json:"last_refresh"`
-	LastRefreshFormatted string        `json:"formatted_last_refresh"`
-	LastRefreshHumanized string        `json:"humanized_last_refresh"`
-	DidError             bool          `json:"did_error"`
+	Checks               []check.Score        `json:"checks"`
+	Average              float64              `json:"average"`
+	Grade                check.Grade          `json:"grade"`
+	Files                int                  `json:"files"`
+	Issues               int                  `json:"issues"`
+	Repo                 string               `json:"repo"`
+	Version              string               `json:"version"`
+	ResolvedRepo         string               `json:"resolvedRepo"`
+	LastRefresh          time.Time            `json:"last_refresh"`
+	LastRefreshFormatted string               `json:"formatted_last_refresh"`
+	LastRefreshHumanized string               `json:"humanized_last_refresh"`
+	DidError             bool                 `json:"did_error"`
+	Config               *check.Config        `json:"config,omitempty"`
+	TimedOut             []string             `json:"timed_out,omitempty"`
+	Skipped              []string             `json:"skipped,omitempty"`
+	SkippedFiles         []check.SkippedFile  `json:"skipped_files,omitempty"`
+	Modules              []check.ModuleResult `json:"modules,omitempty"`
+	Targets              []string             `json:"targets,omitempty"`
+	License              *check.LicenseInfo   `json:"license,omitempty"`
 }
 
-func newChecksResp(db *badger.DB, repo string, forceRefresh bool) (checksResp, error) {
+func newChecksResp(ctx context.Context, db *badger.DB, repo string, forceRefresh bool, vulnDB string) (checksResp, error) {
 	if !forceRefresh {
 		resp, err := getFromCache(db, repo)
 		if err != nil {
@@ -99,7 +107,7 @@ func newChecksResp(db *badger.DB, repo string, forceRefresh bool) (checksResp, e
 		return checksResp{}, fmt.Errorf("could not download repo: %v", err)
 	}
 
-	checkResult, err := check.Run(dirName(repo, ver), false)
+	checkResult, err := check.RunOptions(ctx, dirName(repo, ver), false, check.Options{VulnDB: vulnDB})
 	if err != nil {
 		return checksResp{}, err
 	}
@@ -125,6 +133,13 @@ func newChecksResp(db *badger.DB, repo string, forceRefresh bool) (checksResp, e
 		LastRefreshFormatted: t.Format(time.UnixDate),
 		LastRefreshHumanized: humanize.Time(t),
 		DidError:             checkResult.DidError,
+		Config:               &checkResult.Config,
+		TimedOut:             checkResult.TimedOut,
+		Skipped:              checkResult.Skipped,
+		SkippedFiles:         checkResult.SkippedFiles,
+		Modules:              checkResult.Modules,
+		Targets:              checkResult.Targets,
+		License:              checkResult.License,
 	}
 
 	respBytes, err := json.Marshal(resp)
@@ -156,8 +171,13 @@ func newChecksResp(db *badger.DB, repo string, forceRefresh bool) (checksResp, e
 
 	isNewRepo = oldRepoBytes == nil
 
-	// if this is a new repo, or the user force-refreshed, update the cache
-	if isNewRepo || forceRefresh {
+	// if this is a new repo, or the user force-refreshed, update the cache,
+	// unless checks timed out, as they do when the client goes away, since
+	// the partial result would otherwise stay cached
+	switch {
+	case len(resp.TimedOut) > 0:
+		log.Printf("Not saving repo %q to cache: %s timed out", repo, strings.Join(resp.TimedOut, ", "))
+	case isNewRepo || forceRefresh:
 		err = db.Update(func(txn *badger.Txn) error {
 			log.Printf("Saving repo %q to cache...", repo)
 
@@ -23,6 +23,8 @@ var (
 
 	databasePath = flag.String("db", getEnv("GRC_DATABASE_PATH", "/usr/local/badger"), "path to local badger database")
 
+	vulnDB = flag.String("vulndb", getEnv("GRC_VULN_DB", ""), "path to a local mirror of the Go vulnerability database, used by the vulncheck check")
+
 	//go:embed assets/*
 	embedFS embed.FS
 )
@@ -136,9 +138,13 @@ func main() {
 	m := setupMetrics()
 
 	http.HandleFunc(m.instrument("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assetsFS))).ServeHTTP))
-	http.HandleFunc(m.instrument("/checks", injectBadgerHandler(db, handlers.CheckHandler)))
+	http.HandleFunc(m.instrument("/checks", injectBadgerHandler(db, func(w http.ResponseWriter, r *http.Request, db *badger.DB) {
+		handlers.CheckHandler(w, r, db, *vulnDB)
+	})))
 	http.HandleFunc(m.instrument("/report/", makeHandler(db, "report", gh.ReportHandler)))
-	http.HandleFunc(m.instrument("/badge/", makeHandler(db, "badge", handlers.BadgeHandler)))
+	http.HandleFunc(m.instrument("/badge/", makeHandler(db, "badge", func(w http.ResponseWriter, r *http.Request, db *badger.DB, repo string) {
+		handlers.BadgeHandler(w, r, db, repo, *vulnDB)
+	})))
 	http.HandleFunc(m.instrument("/high_scores/", injectBadgerHandler(db, gh.HighScoresHandler)))
 	http.HandleFunc(m.instrument("/supporters/", gh.SupportersHandler))
 	http.HandleFunc(m.instrument("/about/", gh.AboutHandler))